@@ -5,7 +5,10 @@ package algorithms
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
@@ -20,12 +23,28 @@ type Algorithm interface {
 	SetWidth(K int)
 }
 
-// Counters allow to track how often an algorithm had to backtrack, and at which level, and the toplevel completion as
-// a percentage value between [0,1)
+// Counters allow to track how often an algorithm had to backtrack, and at which level, how far the top-level
+// separator search has progressed, and how often the cache could be used. Levels start at 1 for the top level. Copies
+// made with CopyRef share their values, so that they can be read while a search is running.
 type Counters struct {
-	backtrack          map[int]int
-	cacheMux           *sync.RWMutex
-	topLevelCompletion []lib.Generator
+	backtrack map[int]int
+	cacheMux  *sync.RWMutex
+	stats     *searchStats
+}
+
+// searchStats holds the counters updated atomically during a search
+type searchStats struct {
+	checked      int64   // checks made in the top-level separator search
+	separators   int64   // separators tested at any level
+	balanced     int64   // separators that passed the check of a separator search
+	subedges     int64   // subedge variants tried, after the separators found by the search failed
+	cacheLookups int64   // lookups in the cache of negative results
+	cacheHits    int64   // lookups which allowed to skip a separator
+	cacheEntries int64   // negative results added to the cache
+	total        float64 // size of the top-level search space, 0 if unknown; guarded by cacheMux
+
+	// caches of the searches currently running, with the number of searches using each; guarded by cacheMux
+	caches map[*lib.Cache]int
 }
 
 // CopyRef allows for safe copying of a cache by reference, not value
@@ -43,7 +62,7 @@ func (c *Counters) CopyRef(other *Counters) {
 
 	other.backtrack = c.backtrack
 	other.cacheMux = c.cacheMux
-
+	other.stats = c.stats
 }
 
 // Init is used to set up the Counters struct
@@ -52,26 +71,176 @@ func (c *Counters) Init() {
 		var mux sync.RWMutex
 		c.cacheMux = &mux
 		c.backtrack = make(map[int]int)
+		c.stats = &searchStats{caches: make(map[*lib.Cache]int)}
 	}
 }
 
 // AddBacktrack enables a thread-safe way to add new backtracks to the counter
 func (c *Counters) AddBacktrack(level int) {
+	if c == nil {
+		return
+	}
 	c.cacheMux.Lock()
 	defer c.cacheMux.Unlock()
 
 	c.backtrack[level] = c.backtrack[level] + 1
 }
 
+// AddCacheLookup counts a lookup in the cache, and whether it was a hit
+func (c *Counters) AddCacheLookup(hit bool) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.stats.cacheLookups, 1)
+	if hit {
+		atomic.AddInt64(&c.stats.cacheHits, 1)
+	}
+}
+
+// AddCacheEntry counts a negative result added to the cache
+func (c *Counters) AddCacheEntry() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.stats.cacheEntries, 1)
+}
+
+// trackCache includes the cache in the size reported by Progress, until the returned function is called at the end
+// of the search using it
+func (c *Counters) trackCache(cache *lib.Cache) func() {
+	if c == nil {
+		return func() {}
+	}
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
+	c.stats.caches[cache]++
+
+	return func() {
+		c.cacheMux.Lock()
+		defer c.cacheMux.Unlock()
+		c.stats.caches[cache]--
+		if c.stats.caches[cache] == 0 {
+			delete(c.stats.caches, cache)
+		}
+	}
+}
+
+// AddSeparator counts a separator tested outside of a separator search
+func (c *Counters) AddSeparator() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.stats.separators, 1)
+}
+
+// AddSubedge counts a subedge variant of a separator being tried
+func (c *Counters) AddSubedge() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.stats.subedges, 1)
+}
+
+// countingPredicate counts each check made with a predicate, and the separators passing it
+type countingPredicate struct {
+	pred     lib.Predicate
+	stats    *searchStats
+	topLevel bool
+}
+
+func (p countingPredicate) Check(H *lib.Graph, sep *lib.Edges, balFactor int, ws *lib.CompWorkspace) bool {
+	if p.topLevel {
+		atomic.AddInt64(&p.stats.checked, 1)
+	}
+	atomic.AddInt64(&p.stats.separators, 1)
+
+	output := p.pred.Check(H, sep, balFactor, ws)
+	if output {
+		atomic.AddInt64(&p.stats.balanced, 1)
+	}
+	return output
+}
+
+// track returns the predicate to use in a separator search over combinations of k out of n edges at the given level,
+// which counts the separators tested. At the top level, a new search is started: its search space is recorded and
+// its checks are counted.
+func (c *Counters) track(level int, pred lib.Predicate, n, k int, unextended bool) lib.Predicate {
+	if c == nil {
+		return pred
+	}
+	if level != 1 {
+		return countingPredicate{pred: pred, stats: c.stats}
+	}
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
+
+	c.stats.total = lib.CombinationCount(n, k, unextended)
+	atomic.StoreInt64(&c.stats.checked, 0)
+
+	return countingPredicate{pred: pred, stats: c.stats, topLevel: true}
+}
+
+// Progress is a snapshot of the counters
+type Progress struct {
+	Completion   float64     // share of the current top-level search space checked, -1 if unknown
+	Backtracks   map[int]int // backtracks per level
+	Separators   int64       // separators tested at any level
+	Balanced     int64       // separators that passed the check of a separator search
+	Subedges     int64       // subedge variants of separators tried
+	CacheLookups int64
+	CacheHits    int64
+	CacheEntries int64 // negative results added to the cache
+	CacheSize    int64 // current number of bindings in the caches of the searches still running
+}
+
+// Progress returns a snapshot of the counters. The completion is only known for algorithms using a separator search,
+// and refers to the latest top-level search, which is restarted for each width and each part found by preprocessing.
+func (c *Counters) Progress() Progress {
+	output := Progress{Completion: -1, Backtracks: make(map[int]int)}
+	if c == nil || c.backtrack == nil {
+		return output
+	}
+	c.cacheMux.RLock()
+	defer c.cacheMux.RUnlock()
+
+	for level, v := range c.backtrack {
+		output.Backtracks[level] = v
+	}
+	if c.stats.total > 0 {
+		output.Completion = math.Min(float64(atomic.LoadInt64(&c.stats.checked))/c.stats.total, 1)
+	}
+	output.Separators = atomic.LoadInt64(&c.stats.separators)
+	output.Balanced = atomic.LoadInt64(&c.stats.balanced)
+	output.Subedges = atomic.LoadInt64(&c.stats.subedges)
+	output.CacheLookups = atomic.LoadInt64(&c.stats.cacheLookups)
+	output.CacheHits = atomic.LoadInt64(&c.stats.cacheHits)
+	output.CacheEntries = atomic.LoadInt64(&c.stats.cacheEntries)
+	for cache := range c.stats.caches {
+		output.CacheSize += int64(cache.Len())
+	}
+
+	return output
+}
+
 func (c *Counters) String() string {
 	var buffer bytes.Buffer
+	p := c.Progress()
 
-	for k, v := range c.backtrack {
-		buffer.WriteString(fmt.Sprintln("Found ", v, " backtracks at level ", k))
+	var levels []int
+	for k := range p.Backtracks {
+		levels = append(levels, k)
+	}
+	sort.Ints(levels)
+	for _, k := range levels {
+		buffer.WriteString(fmt.Sprintln("Found ", p.Backtracks[k], " backtracks at level ", k))
 	}
 
-	// nominTotal, denomTotal := lib.GetPercentagesSlice(c.topLevelCompletion)
-	// buffer.WriteString(fmt.Sprintln("Toplevel completion of was ", nominTotal, denomTotal, float32(nominTotal)/float32(denomTotal), "%"))
+	if p.Completion >= 0 {
+		buffer.WriteString(fmt.Sprintf("Toplevel completion: %.2f%%\n", p.Completion*100))
+	}
+	if p.CacheLookups > 0 {
+		buffer.WriteString(fmt.Sprintf("Cache hits: %v of %v lookups\n", p.CacheHits, p.CacheLookups))
+	}
 
 	return buffer.String()
 }
@@ -79,5 +248,22 @@ func (c *Counters) String() string {
 // An AlgorithmDebug exports internal counters to see how far the computation has progressed. To be extracted in case
 // of a timeout.
 type AlgorithmDebug interface {
-	GetCounters() Counters // GetCounters returns the counters collected during a run
+	GetCounters() Counters   // GetCounters returns the counters collected during a run
+	SetCounters(c *Counters) // SetCounters sets the counters to update during a run, nil to stop counting
+}
+
+// getCounters returns a copy of counters which may be nil
+func getCounters(c *Counters) Counters {
+	if c == nil {
+		return Counters{}
+	}
+	return *c
+}
+
+// newNode creates a node of a decomposition, with the given cost and children
+func newNode(bag []int, cover lib.Edges, cost float64, children ...lib.Node) lib.Node {
+	output := lib.NewNode(bag, cover)
+	output.Cost = cost
+	output.Children = children
+	return output
 }
@@ -2,10 +2,8 @@ package algorithms
 
 import (
 	"reflect"
-	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepGlobal implements the global Balanced Separator algorithm.
@@ -15,6 +13,7 @@ type BalSepGlobal struct {
 	Graph     lib.Graph
 	BalFactor int
 	Generator lib.SearchGenerator
+	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,18 +26,35 @@ func (b *BalSepGlobal) SetWidth(K int) {
 	b.K = K
 }
 
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *BalSepGlobal) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
 func (b BalSepGlobal) findGHD() lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1)
 }
 
 // FindDecomp finds a decomp
 func (b BalSepGlobal) FindDecomp() lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1)
 }
 
 // FindDecompGraph finds a decomp, for an explicit lib.Graph
 func (b BalSepGlobal) FindDecompGraph(G lib.Graph) lib.Decomp {
-	return b.findDecomp(G)
+	return b.findDecomp(G, 1)
+}
+
+// SetCounters sets the counters to update during the search
+func (b *BalSepGlobal) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b BalSepGlobal) GetCounters() Counters {
+	return getCounters(b.counters)
 }
 
 // Name returns the name of the algorithm
@@ -52,12 +68,11 @@ func baseCaseSmart(g lib.Graph, H lib.Graph) lib.Decomp {
 
 	if H.Edges.Len() <= 2 && len(H.Special) == 0 {
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges}}
+			Root: lib.NewNode(H.Vertices(), H.Edges)}
 	} else if H.Edges.Len() == 1 && len(H.Special) == 1 {
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges,
-				Children: []lib.Node{{Bag: sp1.Vertices(), Cover: sp1}}}}
+			Root: newNode(H.Edges.Vertices(), H.Edges, 0, lib.NewNode(sp1.Vertices(), sp1))}
 	} else {
 		return baseCase(g, H)
 	}
@@ -73,13 +88,12 @@ func baseCase(g lib.Graph, H lib.Graph) lib.Decomp {
 	case 1:
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1}}
+			Root: lib.NewNode(sp1.Vertices(), sp1)}
 	case 2:
 		sp1 := H.Special[0]
 		sp2 := H.Special[1]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1,
-				Children: []lib.Node{{Bag: sp2.Vertices(), Cover: sp2}}}}
+			Root: newNode(sp1.Vertices(), sp1, 0, lib.NewNode(sp2.Vertices(), sp2))}
 	}
 	return output
 }
@@ -87,15 +101,15 @@ func baseCase(g lib.Graph, H lib.Graph) lib.Decomp {
 func earlyTermination(H lib.Graph) lib.Decomp {
 	//We assume that H as less than K edges, and only one special edge
 	return lib.Decomp{Graph: H,
-		Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges,
-			Children: []lib.Node{{Bag: H.Special[0].Vertices(), Cover: H.Special[0]}}}}
+		Root: newNode(H.Edges.Vertices(), H.Edges, 0, lib.NewNode(H.Special[0].Vertices(), H.Special[0]))}
 }
 
 func rerooting(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp) lib.Decomp {
 	//Create a new GHD for H
-	rerootNode := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
-	output := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
+	rerootNode := lib.NewNode(balsep.Vertices(), balsep)
+	output := lib.NewNode(balsep.Vertices(), balsep)
 
+	lib.SortDecomps(subtrees) // the subtrees arrive in any order
 	for _, s := range subtrees {
 		// fmt.Println("H ", H, "balsep ", balsep, "comp ", s.Graph)
 		s.Root = s.Root.Reroot(rerootNode)
@@ -106,7 +120,7 @@ func rerooting(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp) lib.Decomp
 	return lib.Decomp{Graph: H, Root: output}
 }
 
-func (b BalSepGlobal) findDecomp(H lib.Graph) lib.Decomp {
+func (b BalSepGlobal) findDecomp(H lib.Graph, level int) lib.Decomp {
 	// log.Printf("Current SubGraph: %+v\n", H)
 
 	//stop if there are at most two special edges left
@@ -122,10 +136,10 @@ func (b BalSepGlobal) findDecomp(H lib.Graph) lib.Decomp {
 	var balsep lib.Edges
 
 	edges := lib.FilterVerticesStrict(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), false)
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), false)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
-	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	pred := b.counters.track(level, lib.BalancedCheck{}, edges.Len(), b.K, false)
+	var ws lib.CompWorkspace
 	parallelSearch.FindNext(pred) // initial Search
 
 OUTER:
@@ -134,7 +148,7 @@ OUTER:
 
 		// log.Printf("Balanced Sep chosen: %+v\n", Graph{Edges: balsep})
 
-		comps, _, _ := H.GetComponents(balsep, Vertices)
+		comps, _, _ := H.GetComponents(balsep, &ws)
 
 		// log.Printf("Comps of Sep: %+v\n", comps)
 
@@ -146,13 +160,14 @@ OUTER:
 		for i := range comps {
 			go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
 				comps[i].Special = append(comps[i].Special, SepSpecial)
-				ch <- b.findDecomp(comps[i])
+				ch <- b.findDecomp(comps[i], level+1)
 			}(i, comps, SepSpecial)
 		}
 
 		for i := 0; i < len(comps); i++ {
 			decomp := <-ch
 			if reflect.DeepEqual(decomp, lib.Decomp{}) {
+				b.counters.AddBacktrack(level)
 				// log.Printf("REJECTING %v: couldn't decompose %v with SP %v \n", Graph{Edges: balsep}, comps[i],
 				//  append(compsSp[i], SepSpecial))
 				subtrees = []lib.Decomp{}
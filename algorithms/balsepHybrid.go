@@ -5,11 +5,9 @@ package algorithms
 
 import (
 	"reflect"
-	"runtime"
 	"strconv"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepHybrid implements a hybridised algorithm, using BalSep Local and DetKDecomp in tandem
@@ -19,6 +17,7 @@ type BalSepHybrid struct {
 	BalFactor int
 	Depth     int // how many rounds of balSep are used
 	Generator lib.SearchGenerator
+	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
@@ -31,6 +30,13 @@ func (b *BalSepHybrid) SetWidth(K int) {
 	b.K = K
 }
 
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *BalSepHybrid) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
 func (b BalSepHybrid) findGHD(currentGraph lib.Graph) lib.Decomp {
 	return b.findDecomp(b.Depth, currentGraph)
 }
@@ -45,6 +51,16 @@ func (b BalSepHybrid) FindDecompGraph(G lib.Graph) lib.Decomp {
 	return b.findGHD(G)
 }
 
+// SetCounters sets the counters to update during the search
+func (b *BalSepHybrid) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b BalSepHybrid) GetCounters() Counters {
+	return getCounters(b.counters)
+}
+
 // Name returns the name of the algorithm
 func (b BalSepHybrid) Name() string {
 	return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(b.Depth+1)
@@ -59,6 +75,7 @@ func decrease(count int) int {
 }
 
 func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
+	level := b.Depth - currentDepth + 1 // the top level is 1, as in DetKDecomp
 	// log.Println("Current Depth: ", (b.Depth - currentDepth))
 	// log.Printf("Current SubGraph: %+v\n", H)
 	// log.Printf("Current Special Edges: %+v\n\n", Sp)
@@ -77,17 +94,18 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 
 	//find a balanced separator
 	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), true)
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), true)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
-	parallelSearch.FindNext(pred) // initial Search
+	tracked := b.counters.track(level, pred, edges.Len(), b.K, true)
+	var ws lib.CompWorkspace
+	parallelSearch.FindNext(tracked) // initial Search
 
 	var cache map[uint32]struct{}
 	cache = make(map[uint32]struct{})
 
 	// OUTER:
-	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(tracked) {
 		balsep = lib.GetSubset(edges, parallelSearch.GetResult())
 
 		//  balsepOrig := balsep
@@ -98,7 +116,7 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 
 	INNER:
 		for !exhaustedSubedges {
-			comps, _, _ := H.GetComponents(balsep, Vertices)
+			comps, _, _ := H.GetComponents(balsep, &ws)
 
 			// log.Printf("Comps of Sep: %+v\n", comps)
 
@@ -132,10 +150,11 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 							return
 						}
 
-						det := DetKDecomp{K: b.K, Graph: b.Graph, BalFactor: b.BalFactor, SubEdge: true}
+						det := DetKDecomp{K: b.K, Graph: b.Graph, BalFactor: b.BalFactor, SubEdge: true,
+							Cancel: cancelOf(b.Generator), counters: b.counters}
 						det.cache.Init()
 
-						result := det.findDecomp(comps[i], balsep.Vertices(), 0)
+						result := det.findDecomp(comps[i], balsep.Vertices(), level)
 						if !reflect.DeepEqual(result, lib.Decomp{}) {
 							result.SkipRerooting = true
 						} else {
@@ -157,6 +176,7 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 			for i := 0; i < len(comps); i++ {
 				decomp := <-ch
 				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+					b.counters.AddBacktrack(level)
 					// log.Printf("balDet REJECTING %v: couldn't decompose a component of H %v \n",
 					//        Graph{Edges: balsep}, H)
 					// log.Println("\n\nCurrent Depth: ", (b.Depth - currentDepth))
@@ -172,12 +192,13 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 					for !nextBalsepFound {
 						if sepSub.HasNext() {
 							balsep = sepSub.GetCurrent()
+							b.counters.AddSubedge()
 							_, ok := cache[lib.IntHash(balsep.Vertices())]
 							if ok { //skip since already seen
 								continue thisLoop
 							}
 
-							if pred.Check(&H, &balsep, b.BalFactor, Vertices) {
+							if pred.Check(&H, &balsep, b.BalFactor, &ws) {
 								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
 								nextBalsepFound = true
 							}
@@ -192,8 +213,9 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 				subtrees = append(subtrees, decomp)
 			}
 
-			output := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
+			output := lib.NewNode(balsep.Vertices(), balsep)
 
+			lib.SortDecomps(subtrees) // the subtrees arrive in any order
 			for _, s := range subtrees {
 				//TODO: Reroot only after all subtrees received
 				if currentDepth == 0 && s.SkipRerooting {
@@ -202,7 +224,7 @@ func (b BalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 					// decomp_deux := local.findDecomp(K, comps[i], append(compsSp[i], SepSpecial))
 					// fmt.Println("Output from Balsep: ", decomp_deux)
 				} else {
-					s.Root = s.Root.Reroot(lib.Node{Bag: balsep.Vertices(), Cover: balsep})
+					s.Root = s.Root.Reroot(lib.NewNode(balsep.Vertices(), balsep))
 					s.Root = s.Root.Children[0]
 					// log.Printf("Produced Decomp (with balsep %v): %+v\n", balsep, decomp)
 				}
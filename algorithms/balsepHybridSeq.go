@@ -5,7 +5,6 @@ import (
 	"strconv"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepHybridSeq is a purely sequential version of BalSepHybrid
@@ -15,6 +14,7 @@ type BalSepHybridSeq struct {
 	BalFactor int
 	Depth     int // how many rounds of balSep are used
 	Generator lib.SearchGenerator
+	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,6 +27,13 @@ func (s *BalSepHybridSeq) SetWidth(K int) {
 	s.K = K
 }
 
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (s *BalSepHybridSeq) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *s
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: s.Generator}
+	return &output
+}
+
 func (s BalSepHybridSeq) findGHD(currentGraph lib.Graph) lib.Decomp {
 	return s.findDecomp(s.Depth, currentGraph)
 }
@@ -41,12 +48,23 @@ func (s BalSepHybridSeq) FindDecompGraph(G lib.Graph) lib.Decomp {
 	return s.findGHD(G)
 }
 
+// SetCounters sets the counters to update during the search
+func (s *BalSepHybridSeq) SetCounters(c *Counters) {
+	s.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (s BalSepHybridSeq) GetCounters() Counters {
+	return getCounters(s.counters)
+}
+
 // Name returns the name of the algorithm
 func (s BalSepHybridSeq) Name() string {
 	return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(s.Depth+1)
 }
 
 func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
+	level := s.Depth - currentDepth + 1 // the top level is 1, as in DetKDecomp
 	// log.Println("Current Depth: ", (b.Depth - currentDepth))
 	// log.Printf("Current SubGraph: %+v\n", H)
 	// log.Printf("Current Special Edges: %+v\n\n", Sp)
@@ -68,14 +86,15 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 	generators := lib.SplitCombin(edges.Len(), s.K, 1, true) // create just one goroutine, making this sequential
 	parallelSearch := s.Generator.GetSearch(&H, &edges, s.BalFactor, generators)
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
-	parallelSearch.FindNext(pred) // initial Search
+	tracked := s.counters.track(level, pred, edges.Len(), s.K, true)
+	var ws lib.CompWorkspace
+	parallelSearch.FindNext(tracked) // initial Search
 
 	var cache map[uint32]struct{}
 	cache = make(map[uint32]struct{})
 
 	// OUTER:
-	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(tracked) {
 
 		balsep = lib.GetSubset(edges, parallelSearch.GetResult())
 
@@ -87,7 +106,7 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 
 	INNER:
 		for !exhaustedSubedges {
-			comps, _, _ := H.GetComponents(balsep, Vertices)
+			comps, _, _ := H.GetComponents(balsep, &ws)
 
 			// log.Printf("Comps of Sep: %+v\n", comps)
 
@@ -125,14 +144,15 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 
 						}
 
-						det := DetKDecomp{K: s.K, Graph: s.Graph, BalFactor: s.BalFactor, SubEdge: true}
+						det := DetKDecomp{K: s.K, Graph: s.Graph, BalFactor: s.BalFactor, SubEdge: true,
+							Cancel: cancelOf(s.Generator), counters: s.counters}
 
 						// edgesFromSpecial := EdgesSpecial(Sp)
 						// comps[i].Edges.Append(edgesFromSpecial...)
 
 						// det.cache = make(map[uint64]*CompCache)
 						det.cache.Init()
-						result := det.findDecomp(comps[i], balsep.Vertices(), 0)
+						result := det.findDecomp(comps[i], balsep.Vertices(), level)
 						if !reflect.DeepEqual(result, lib.Decomp{}) && currentDepth == 0 {
 							result.SkipRerooting = true
 						}
@@ -147,6 +167,7 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 			for i := range outDecomps {
 				decomp := outDecomps[i]
 				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+					s.counters.AddBacktrack(level)
 					// log.Printf("balDet REJECTING %v: couldn't decompose a component of H %v \n",
 					//        Graph{Edges: balsep}, H)
 					// log.Println("\n\nCurrent Depth: ", (b.Depth - currentDepth))
@@ -162,12 +183,13 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 					for !nextBalsepFound {
 						if sepSub.HasNext() {
 							balsep = sepSub.GetCurrent()
+							s.counters.AddSubedge()
 							_, ok := cache[lib.IntHash(balsep.Vertices())]
 							if ok { //skip since already seen
 								continue thisLoop
 							}
 
-							if pred.Check(&H, &balsep, s.BalFactor, Vertices) {
+							if pred.Check(&H, &balsep, s.BalFactor, &ws) {
 								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
 								nextBalsepFound = true
 							}
@@ -184,7 +206,7 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 				subtrees = append(subtrees, decomp)
 			}
 
-			output := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
+			output := lib.NewNode(balsep.Vertices(), balsep)
 
 			for _, s := range subtrees {
 				if currentDepth == 0 && s.SkipRerooting {
@@ -193,7 +215,7 @@ func (s BalSepHybridSeq) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
 					// decomp_deux := local.findDecomp(K, comps[i], append(compsSp[i], SepSpecial))
 					// fmt.Println("Output from Balsep: ", decomp_deux)
 				} else {
-					s.Root = s.Root.Reroot(lib.Node{Bag: balsep.Vertices(), Cover: balsep})
+					s.Root = s.Root.Reroot(lib.NewNode(balsep.Vertices(), balsep))
 					s.Root = s.Root.Children[0]
 					// log.Printf("Produced Decomp (with balsep %v): %+v\n", balsep, decomp)
 				}
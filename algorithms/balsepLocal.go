@@ -2,10 +2,8 @@ package algorithms
 
 import (
 	"reflect"
-	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepLocal implements the local Balanced Separator algorithm for computing GHDs.
@@ -15,6 +13,8 @@ type BalSepLocal struct {
 	Graph     lib.Graph
 	BalFactor int
 	Generator lib.SearchGenerator
+	Tracer    *lib.Tracer // if set, each recursion step is recorded
+	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
@@ -27,18 +27,35 @@ func (b *BalSepLocal) SetWidth(K int) {
 	b.K = K
 }
 
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *BalSepLocal) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
+// SetCounters sets the counters to update during the search
+func (b *BalSepLocal) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b BalSepLocal) GetCounters() Counters {
+	return getCounters(b.counters)
+}
+
 func (b BalSepLocal) findGHD(K int) lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1, 0)
 }
 
 // FindDecomp finds a decomp
 func (b BalSepLocal) FindDecomp() lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1, 0)
 }
 
 // FindDecompGraph finds a decomp, for an explicit graph
 func (b BalSepLocal) FindDecompGraph(G lib.Graph) lib.Decomp {
-	return b.findDecomp(G)
+	return b.findDecomp(G, 1, 0)
 }
 
 // Name returns the name of the algorithm
@@ -57,14 +74,15 @@ func searchSubEdge(g *BalSepLocal, H *lib.Graph, balsepOrig lib.Edges, sepSub *l
 	}
 	nextBalsepFound := false
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
 
 	for !nextBalsepFound {
 		if sepSub.HasNext() {
 			balsep = sepSub.GetCurrent()
+			g.counters.AddSubedge()
 			// log.Printf("Testing SSSep: %v of %v , Special Edges %v \n", Graph{Edges: balsep},
 			//        Graph{Edges: balsepOrig}, Sp)
-			if pred.Check(H, &balsep, g.BalFactor, Vertices) {
+			if pred.Check(H, &balsep, g.BalFactor, &ws) {
 				nextBalsepFound = true
 			}
 		} else {
@@ -75,7 +93,16 @@ func searchSubEdge(g *BalSepLocal, H *lib.Graph, balsepOrig lib.Edges, sepSub *l
 	return balsep
 }
 
-func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
+// findDecomp decomposes H as a new recursion step, called by the step parent
+func (b BalSepLocal) findDecomp(H lib.Graph, level int, parent int64) lib.Decomp {
+	step := b.Tracer.Enter(parent, level, b.K, H)
+	output := b.decompose(H, level, step)
+	b.Tracer.Exit(step, !reflect.DeepEqual(output, lib.Decomp{}))
+
+	return output
+}
+
+func (b BalSepLocal) decompose(H lib.Graph, level int, step int64) lib.Decomp {
 	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
 
 	//stop if there are at most two special edges left
@@ -90,16 +117,17 @@ func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 	var balsep lib.Edges
 
 	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), true)
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), true)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
-	parallelSearch.FindNext(pred) // initial Search
+	tracked := b.counters.track(level, pred, edges.Len(), b.K, true)
+	var ws lib.CompWorkspace
+	parallelSearch.FindNext(tracked) // initial Search
 
 	var cache map[uint32]struct{}
 	cache = make(map[uint32]struct{})
 
-	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+	for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(tracked) {
 
 		balsep = lib.GetSubset(edges, parallelSearch.GetResult())
 
@@ -111,7 +139,8 @@ func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 
 	INNER:
 		for !exhaustedSubedges {
-			comps, _, _ := H.GetComponents(balsep, Vertices)
+			comps, _, _ := H.GetComponents(balsep, &ws)
+			b.Tracer.Separator(step, balsep, sepSub != nil, comps)
 
 			// log.Printf("Comps of Sep: %v for H %v \n", comps, H)
 
@@ -123,13 +152,14 @@ func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 			for i := range comps {
 				go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
 					comps[i].Special = append(comps[i].Special, SepSpecial)
-					ch <- b.findDecomp(comps[i])
+					ch <- b.findDecomp(comps[i], level+1, step)
 				}(i, comps, SepSpecial)
 			}
 
 			for i := 0; i < len(comps); i++ {
 				decomp := <-ch
 				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+					b.counters.AddBacktrack(level)
 					subtrees = []lib.Decomp{}
 					if sepSub == nil {
 						sepSub = lib.GetSepSub(b.Graph.Edges, balsep, b.K)
@@ -139,6 +169,7 @@ func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 					for !nextBalsepFound {
 						if sepSub.HasNext() {
 							balsep = sepSub.GetCurrent()
+							b.counters.AddSubedge()
 							if len(balsep.Vertices()) == 0 {
 								continue thisLoop
 							}
@@ -146,7 +177,7 @@ func (b BalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 							if ok { //skip since already seen
 								continue thisLoop
 							}
-							if pred.Check(&H, &balsep, b.BalFactor, Vertices) {
+							if pred.Check(&H, &balsep, b.BalFactor, &ws) {
 								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
 								nextBalsepFound = true
 							}
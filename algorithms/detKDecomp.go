@@ -5,7 +5,6 @@ import (
 	"reflect"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // DetKDecomp computes for a graph and some width K a HD of width K if it exists
@@ -14,13 +13,16 @@ type DetKDecomp struct {
 	Graph     lib.Graph
 	BalFactor int
 	SubEdge   bool
+	JCosts    lib.CostModel // if set, covers are tried in the order of their costs
+	Cancel    <-chan struct{} // if closed, the search is abandoned and the decomposition rejected
 	cache     lib.Cache
 	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
 func (d *DetKDecomp) SetGenerator(Gen lib.SearchGenerator) {
-	// detkdecomp doesn't use parallel search, this stub is needed to fit the general interface
+	// detkdecomp doesn't use parallel search, only a cancellation is taken over
+	d.Cancel = cancelOf(Gen)
 }
 
 // SetWidth sets the current width parameter of the algorithm
@@ -30,8 +32,20 @@ func (d *DetKDecomp) SetWidth(K int) {
 	d.K = K
 }
 
+// GetCosts returns the join costs considered by the search
+func (d *DetKDecomp) GetCosts() lib.CostModel {
+	return d.JCosts
+}
+
+// Copy returns an independent instance of the algorithm, with an empty cache, whose search ends once cancel is closed
+func (d *DetKDecomp) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	return &DetKDecomp{K: d.K, Graph: d.Graph, BalFactor: d.BalFactor, SubEdge: d.SubEdge, JCosts: d.JCosts,
+		Cancel: cancel, counters: d.counters}
+}
+
 func (d *DetKDecomp) findHD(currentGraph lib.Graph) lib.Decomp {
 	d.cache.Init()
+	defer d.counters.trackCache(&d.cache)()
 	return d.findDecomp(currentGraph, []int{}, 0)
 }
 
@@ -40,6 +54,16 @@ func (d *DetKDecomp) FindDecomp() lib.Decomp {
 	return d.findHD(d.Graph)
 }
 
+// SetCounters sets the counters to update during the search
+func (d *DetKDecomp) SetCounters(c *Counters) {
+	d.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (d *DetKDecomp) GetCounters() Counters {
+	return getCounters(d.counters)
+}
+
 // Name returns the name of the algorithm
 func (d *DetKDecomp) Name() string {
 	if d.SubEdge {
@@ -72,17 +96,17 @@ func baseCaseDetK(H lib.Graph) lib.Decomp {
 
 	switch len(H.Special) {
 	case 0:
-		return lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges}}
+		return lib.Decomp{Graph: H, Root: lib.NewNode(H.Vertices(), H.Edges)}
 
 	case 1:
 		sp1 := H.Special[0]
-		children = lib.Node{Bag: sp1.Vertices(), Cover: sp1}
+		children = lib.NewNode(sp1.Vertices(), sp1)
 	}
 
 	if H.Edges.Len() == 0 {
 		return lib.Decomp{Graph: H, Root: children}
 	}
-	return lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges, Children: []lib.Node{children}}}
+	return lib.Decomp{Graph: H, Root: newNode(H.Vertices(), H.Edges, 0, children)}
 }
 
 func (d *DetKDecomp) findDecomp(H lib.Graph, oldSep []int, recDepth int) lib.Decomp {
@@ -107,21 +131,39 @@ func (d *DetKDecomp) findDecomp(H lib.Graph, oldSep []int, recDepth int) lib.Dec
 
 	gen := lib.NewCover(d.K, conn, bound, H.Edges.Vertices())
 
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
+
+	var covers [][]int // only used when join costs are present
+	if d.JCosts != nil {
+		covers = orderCovers(d.JCosts, &gen, bound)
+	}
+	nextCover := 0
 
 OUTER:
-	for gen.HasNext {
-		out := gen.NextSubset()
+	for (d.JCosts == nil && gen.HasNext) || nextCover < len(covers) {
+		if lib.Cancelled(d.Cancel) {
+			return lib.Decomp{}
+		}
+
+		var subset []int
 
-		if out == -1 {
-			if gen.HasNext {
-				log.Panicln(" -1 but hasNext not false!")
+		if d.JCosts != nil {
+			subset = covers[nextCover]
+			nextCover++
+		} else {
+			out := gen.NextSubset()
+
+			if out == -1 {
+				if gen.HasNext {
+					log.Panicln(" -1 but hasNext not false!")
+				}
+				continue
 			}
-			continue
+			subset = gen.Subset
 		}
 
 		var sep lib.Edges
-		sep = lib.GetSubset(bound, gen.Subset)
+		sep = lib.GetSubset(bound, subset)
 
 		// if !Subset(conn, sep.Vertices()) {
 		//  log.Panicln("Cover messed up! 137")
@@ -154,7 +196,7 @@ OUTER:
 				var sepConst []lib.Edge
 				var sepChanging []lib.Edge
 				if d.SubEdge {
-					for i, v := range gen.Subset {
+					for i, v := range subset {
 						if gen.InComp[v] {
 							sepChanging = append(sepChanging, sep.Slice()[i])
 						} else {
@@ -170,10 +212,13 @@ OUTER:
 				for true {
 
 					// log.Println("Sep chosen ", sepActual, " out ", out)
-					comps, _, _ := H.GetComponents(sepActual, Vertices)
+					comps, _, _ := H.GetComponents(sepActual, &ws)
+					d.counters.AddSeparator()
 
 					//check cache for previous encounters
-					if d.cache.CheckNegative(sepActual, comps) {
+					cached := d.cache.CheckNegative(sepActual, comps)
+					d.counters.AddCacheLookup(cached)
+					if cached {
 						// log.Println("Skipping sep", sepActual, "due to cache.")
 						if addEdges {
 							iAdd++
@@ -196,6 +241,7 @@ OUTER:
 							}
 
 							d.cache.AddNegative(sepActual, comps[i])
+							d.counters.AddCacheEntry()
 							// log.Printf("detK REJECTING %v: couldn't decompose %v  \n",
 							// 	lib.Graph{Edges: sepActual}, comps[i])
 							// log.Printf("\n\nCurrent oldSep: %v\n", lib.PrintVertices(oldSep))
@@ -211,6 +257,7 @@ OUTER:
 								for !nextBalsepFound {
 									if sepSub.HasNext() {
 										sepActual = sepSub.GetCurrent()
+										d.counters.AddSubedge()
 										sepActual = lib.NewEdges(append(sepActual.Slice(), sepConst...))
 										if connectingSep(sepActual.Vertices(), conn, compVertices) {
 											nextBalsepFound = true
@@ -242,7 +289,12 @@ OUTER:
 						subtrees = append(subtrees, decomp.Root)
 					}
 
-					return lib.Decomp{Graph: H, Root: lib.Node{Bag: bag, Cover: sepActual, Children: subtrees}}
+					var cost float64
+					if d.JCosts != nil {
+						cost = edgesCost(d.JCosts, sepActual)
+					}
+
+					return lib.Decomp{Graph: H, Root: newNode(bag, sepActual, cost, subtrees...)}
 				}
 			}
 		}
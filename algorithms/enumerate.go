@@ -0,0 +1,226 @@
+package algorithms
+
+// enumerate.go implements an iterator over all distinct decompositions that can be found by BalSep Local
+
+import (
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Enumerator iterates over all distinct decompositions of width ≤ K that BalSep Local can produce, using every
+// balanced separator found by the search instead of just the first that works. Two decompositions are considered the
+// same if they are equal up to rerooting and the merging of nodes (see lib.Decomp.CanonicalHash). Subedges are only
+// used for subgraphs that cannot be decomposed otherwise.
+//
+// The enumeration runs in the background, always one decomposition ahead of the caller. Stop must be called if the
+// iterator is not exhausted, to end the enumeration.
+type Enumerator struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Generator lib.SearchGenerator
+	results   chan lib.Decomp
+	stop      chan struct{}
+	stopOnce  sync.Once
+	seen      map[uint64]struct{}
+	current   lib.Decomp
+}
+
+func (e *Enumerator) start() {
+	if e.Generator == nil {
+		e.Generator = lib.ParallelSearchGen{}
+	}
+	e.results = make(chan lib.Decomp)
+	e.stop = make(chan struct{})
+	e.seen = make(map[uint64]struct{})
+
+	go func() {
+		e.enumerate(e.Graph, func(decomp lib.Decomp) bool {
+			select {
+			case e.results <- decomp:
+				return true
+			case <-e.stop:
+				return false
+			}
+		})
+		close(e.results)
+	}()
+}
+
+// HasNext searches for the next distinct decomposition, and returns true if one was found
+func (e *Enumerator) HasNext() bool {
+	if e.results == nil {
+		e.start()
+	}
+	if e.stopped() {
+		return false
+	}
+
+	for decomp := range e.results {
+		hash := decomp.CanonicalHash()
+		if _, ok := e.seen[hash]; ok {
+			continue
+		}
+		e.seen[hash] = lib.Empty
+		e.current = copyDecomp(decomp)
+		return true
+	}
+
+	return false
+}
+
+// GetNext returns the decomposition found by the last call to HasNext
+func (e *Enumerator) GetNext() lib.Decomp {
+	return e.current
+}
+
+// Stop ends the enumeration
+func (e *Enumerator) Stop() {
+	if e.results == nil {
+		return
+	}
+	e.stopOnce.Do(func() { close(e.stop) })
+}
+
+func (e *Enumerator) stopped() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// enumerate passes all decompositions of H to yield, until yield returns false. Returns false if the enumeration was
+// stopped.
+func (e *Enumerator) enumerate(H lib.Graph, yield func(lib.Decomp) bool) bool {
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return yield(baseCaseSmart(e.Graph, H))
+	}
+
+	//Early termination
+	if H.Edges.Len() <= e.K && len(H.Special) == 1 {
+		return yield(earlyTermination(H))
+	}
+
+	edges := lib.CutEdges(e.Graph.Edges, H.Vertices())
+	generators := lib.SplitCombin(edges.Len(), e.K, lib.Workers(e.Generator), false)
+	parallelSearch := e.Generator.GetSearch(&H, &edges, e.BalFactor, generators)
+	pred := lib.BalancedCheck{}
+
+	var separators []lib.Edges
+	found := false
+
+	for parallelSearch.FindNext(pred); !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
+		balsep := lib.GetSubset(edges, parallelSearch.GetResult())
+		separators = append(separators, balsep)
+
+		foundSep, ok := e.enumerateSep(H, balsep, yield)
+		if !ok {
+			return false
+		}
+		found = found || foundSep
+	}
+
+	if found {
+		return true
+	}
+
+	// fall back to subedges
+	var ws lib.CompWorkspace
+	cache := make(map[uint32]struct{})
+
+	for _, sep := range separators {
+		sepSub := lib.GetSepSub(e.Graph.Edges, sep, e.K)
+		for sepSub.HasNext() {
+			balsep := sepSub.GetCurrent()
+			if len(balsep.Vertices()) == 0 {
+				continue
+			}
+			if _, ok := cache[lib.IntHash(balsep.Vertices())]; ok { //skip since already seen
+				continue
+			}
+			cache[lib.IntHash(balsep.Vertices())] = lib.Empty
+
+			if !pred.Check(&H, &balsep, e.BalFactor, &ws) {
+				continue
+			}
+			if _, ok := e.enumerateSep(H, balsep, yield); !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// enumerateSep passes all decompositions of H with balsep at the root to yield. Returns whether any decomposition
+// was found, and false as its second value if the enumeration was stopped.
+func (e *Enumerator) enumerateSep(H lib.Graph, balsep lib.Edges, yield func(lib.Decomp) bool) (bool, bool) {
+	var ws lib.CompWorkspace
+	comps, _, _ := H.GetComponents(balsep, &ws)
+
+	SepSpecial := lib.NewEdges(balsep.Slice())
+	for i := range comps {
+		comps[i].Special = append(comps[i].Special, SepSpecial)
+	}
+
+	if len(comps) == 0 {
+		return true, yield(combineAtSep(H, balsep, SepSpecial, []lib.Decomp{}, 0))
+	}
+
+	// all components but the first are enumerated completely, since they are combined with each decomposition of
+	// the first one
+	rest := make([][]lib.Decomp, len(comps))
+	for i := 1; i < len(comps); i++ {
+		seen := make(map[uint64]struct{})
+		ok := e.enumerate(comps[i], func(decomp lib.Decomp) bool {
+			hash := decomp.CanonicalHash()
+			if _, ok := seen[hash]; !ok {
+				seen[hash] = lib.Empty
+				rest[i] = append(rest[i], decomp)
+			}
+			return !e.stopped()
+		})
+		if !ok || e.stopped() {
+			return false, false
+		}
+		if len(rest[i]) == 0 {
+			return false, true // some component cannot be decomposed
+		}
+	}
+
+	found := false
+	seen := make(map[uint64]struct{})
+	subtrees := make([]lib.Decomp, len(comps))
+
+	var product func(i int) bool
+	product = func(i int) bool {
+		if i == len(comps) {
+			found = true
+			return yield(combineAtSep(H, balsep, SepSpecial, subtrees, 0))
+		}
+		for _, decomp := range rest[i] {
+			subtrees[i] = decomp
+			if !product(i + 1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	ok := e.enumerate(comps[0], func(decomp lib.Decomp) bool {
+		hash := decomp.CanonicalHash()
+		if _, ok := seen[hash]; ok {
+			return true
+		}
+		seen[hash] = lib.Empty
+
+		subtrees[0] = decomp
+		return product(1)
+	})
+
+	return found, ok
+}
@@ -0,0 +1,129 @@
+package algorithms
+
+// hingePortfolio.go implements the choice of a different algorithm for each hinge of a hingetree
+
+import (
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// AcyclicDecomp decomposes α-acyclic graphs directly, by restoring a join tree from their GYÖ reduction. Graphs
+// that are not α-acyclic, or contain special edges, are rejected.
+type AcyclicDecomp struct {
+	Graph lib.Graph
+}
+
+// SetGenerator is a stub, as no search is needed
+func (a *AcyclicDecomp) SetGenerator(Gen lib.SearchGenerator) {}
+
+// SetWidth is a stub, as the width of the produced decompositions is always 1
+func (a *AcyclicDecomp) SetWidth(K int) {}
+
+// Name returns the name of the algorithm
+func (a *AcyclicDecomp) Name() string {
+	return "Acyclic"
+}
+
+// FindDecomp finds a join tree
+func (a *AcyclicDecomp) FindDecomp() lib.Decomp {
+	return a.FindDecompGraph(a.Graph)
+}
+
+// FindDecompGraph finds a join tree, for an explicit graph
+func (a *AcyclicDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
+	if G.Edges.Len() == 0 {
+		return lib.Decomp{}
+	}
+
+	decomp, ok := G.GetJoinTree()
+	if !ok {
+		return lib.Decomp{}
+	}
+
+	return decomp
+}
+
+// HingePortfolio decomposes a graph via its hingetree, choosing an algorithm for each hinge by its size: acyclic
+// hinges are solved directly, hinges with at most Threshold edges by DetKDecomp, and all others by BalSepHybrid.
+// The choice and outcome for each hinge are recorded in Stats.
+type HingePortfolio struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Threshold int // hinges with at most this many edges are decomposed with DetKDecomp
+	Depth     int // the depth used for BalSepHybrid
+	Generator lib.SearchGenerator
+	Stats     []lib.HingeStat // stats of the hinges, from the last call of FindDecompGraph
+	mux       sync.Mutex      // guards Stats, for concurrent calls of FindDecompGraph
+	counters  *Counters
+}
+
+// SetGenerator defines the type of Search to use
+func (p *HingePortfolio) SetGenerator(Gen lib.SearchGenerator) {
+	p.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (p *HingePortfolio) SetWidth(K int) {
+	p.K = K
+}
+
+// SetCounters sets the counters to update during the search, shared by the algorithms chosen for the hinges
+func (p *HingePortfolio) SetCounters(c *Counters) {
+	p.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (p *HingePortfolio) GetCounters() Counters {
+	return getCounters(p.counters)
+}
+
+// Name returns the name of the algorithm
+func (p *HingePortfolio) Name() string {
+	return "Hinge Portfolio"
+}
+
+// Select chooses the algorithm for a hinge. Each hinge gets its own instance, so that they can run in parallel, and
+// its search ends once cancel is closed.
+func (p *HingePortfolio) Select(hinge lib.Graph, cancel <-chan struct{}) lib.AlgorithmH {
+	if len(hinge.Special) == 0 && hinge.IsAlphaAcyclic() {
+		return &AcyclicDecomp{Graph: p.Graph}
+	}
+
+	if hinge.Edges.Len() <= p.Threshold {
+		return &DetKDecomp{K: p.K, Graph: p.Graph, BalFactor: p.BalFactor, Cancel: cancel, counters: p.counters}
+	}
+
+	balDet := &BalSepHybrid{K: p.K, Graph: p.Graph, BalFactor: p.BalFactor, Depth: p.Depth, counters: p.counters}
+	balDet.SetGenerator(lib.CancelSearchGen{Cancel: cancel, Search: p.Generator})
+	return balDet
+}
+
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (p *HingePortfolio) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	return &HingePortfolio{K: p.K, Graph: p.Graph, BalFactor: p.BalFactor, Threshold: p.Threshold, Depth: p.Depth,
+		Generator: lib.CancelSearchGen{Cancel: cancel, Search: p.Generator}, counters: p.counters}
+}
+
+// FindDecomp finds a decomp
+func (p *HingePortfolio) FindDecomp() lib.Decomp {
+	return p.FindDecompGraph(p.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (p *HingePortfolio) FindDecompGraph(G lib.Graph) lib.Decomp {
+	if len(G.Special) > 0 { // hingetrees are only computed for graphs without special edges
+		return p.Select(G, cancelOf(p.Generator)).FindDecompGraph(G)
+	}
+
+	hinget := lib.GetHingeTree(G)
+
+	decomp, stats := hinget.DecompHingeSelect(p, G)
+
+	p.mux.Lock()
+	p.Stats = stats
+	p.mux.Unlock()
+
+	return decomp
+}
@@ -0,0 +1,142 @@
+package algorithms
+
+import (
+	"reflect"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// JCostBalSepGlobal implements the global Balanced Separator algorithm, trying separators in the order of their
+// join costs. This requires all subedges to be added explicitly to the input lib.Graph.
+type JCostBalSepGlobal struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Generator lib.SearchGenerator
+	JCosts    lib.CostModel
+	counters  *Counters
+}
+
+// SetGenerator defines the type of Search to use
+func (b *JCostBalSepGlobal) SetGenerator(Gen lib.SearchGenerator) {
+	b.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (b *JCostBalSepGlobal) SetWidth(K int) {
+	b.K = K
+}
+
+// GetCosts returns the join costs considered by the search
+func (b *JCostBalSepGlobal) GetCosts() lib.CostModel {
+	return b.JCosts
+}
+
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *JCostBalSepGlobal) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
+// FindDecomp finds a decomp
+func (b JCostBalSepGlobal) FindDecomp() lib.Decomp {
+	return b.findDecomp(b.Graph, 1)
+}
+
+// FindDecompGraph finds a decomp, for an explicit lib.Graph
+func (b JCostBalSepGlobal) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return b.findDecomp(G, 1)
+}
+
+// SetCounters sets the counters to update during the search
+func (b *JCostBalSepGlobal) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b JCostBalSepGlobal) GetCounters() Counters {
+	return getCounters(b.counters)
+}
+
+// Name returns the name of the algorithm
+func (b JCostBalSepGlobal) Name() string {
+	return "BalSep Global + Join Optimization"
+}
+
+// superEdges replaces each subedge by the first named edge in the graph that contains it, keeping the positions
+func superEdges(edges lib.Edges, graph lib.Edges) lib.Edges {
+	var output []lib.Edge
+
+OUTER:
+	for _, e := range edges.Slice() {
+		if e.Name == 0 {
+			for _, e2 := range graph.Slice() {
+				if e2.Name != 0 && lib.Subset(e.Vertices, e2.Vertices) {
+					output = append(output, e2)
+					continue OUTER
+				}
+			}
+		}
+		output = append(output, e)
+	}
+
+	return lib.NewEdges(output)
+}
+
+func (b JCostBalSepGlobal) findDecomp(H lib.Graph, level int) lib.Decomp {
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return baseCaseSmartCosts(b.Graph, H, b.JCosts)
+	}
+
+	//Early termination
+	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+		return earlyTerminationCosts(H, b.JCosts)
+	}
+
+	var balsep lib.Edges
+
+	edges := lib.FilterVerticesStrict(b.Graph.Edges, H.Vertices())
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), false)
+	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
+	pred := b.counters.track(level, lib.BalancedCheck{}, edges.Len(), b.K, false)
+	var ws lib.CompWorkspace
+
+	// subedges are costed like the edges they are derived from
+	separators := orderSeparators(b.JCosts, superEdges(edges, b.Graph.Edges), parallelSearch, pred)
+
+OUTER:
+	for _, sep := range separators {
+		balsep = lib.GetSubset(edges, sep.Found)
+
+		comps, _, _ := H.GetComponents(balsep, &ws)
+
+		SepSpecial := lib.NewEdges(balsep.Slice())
+
+		var subtrees []lib.Decomp
+		ch := make(chan lib.Decomp)
+
+		for i := range comps {
+			go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
+				comps[i].Special = append(comps[i].Special, SepSpecial)
+				ch <- b.findDecomp(comps[i], level+1)
+			}(i, comps, SepSpecial)
+		}
+
+		for i := 0; i < len(comps); i++ {
+			decomp := <-ch
+			if reflect.DeepEqual(decomp, lib.Decomp{}) {
+				b.counters.AddBacktrack(level)
+				subtrees = []lib.Decomp{}
+				continue OUTER
+			}
+
+			subtrees = append(subtrees, decomp)
+		}
+
+		return rerootingCosts(H, balsep, subtrees, sep.Cost)
+	}
+
+	return lib.Decomp{} // empty Decomp signifying reject
+}
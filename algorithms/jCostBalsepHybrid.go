@@ -0,0 +1,233 @@
+package algorithms
+
+// Join cost aware version of BalSepHybrid, trying separators in the order of their costs in the BalSep rounds, and
+// covers in the order of their costs in the DetKDecomp phase
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// JCostBalSepHybrid implements a hybridised algorithm, using BalSep Local and DetKDecomp in tandem, choosing
+// separators and covers by their join costs
+type JCostBalSepHybrid struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Depth     int // how many rounds of balSep are used
+	Generator lib.SearchGenerator
+	JCosts    lib.CostModel
+	counters  *Counters
+}
+
+// SetGenerator defines the type of Search to use
+func (b *JCostBalSepHybrid) SetGenerator(Gen lib.SearchGenerator) {
+	b.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (b *JCostBalSepHybrid) SetWidth(K int) {
+	b.K = K
+}
+
+// GetCosts returns the join costs considered by the search
+func (b *JCostBalSepHybrid) GetCosts() lib.CostModel {
+	return b.JCosts
+}
+
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *JCostBalSepHybrid) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
+// FindDecomp finds a decomp
+func (b JCostBalSepHybrid) FindDecomp() lib.Decomp {
+	return b.findDecomp(b.Depth, b.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (b JCostBalSepHybrid) FindDecompGraph(G lib.Graph) lib.Decomp {
+	return b.findDecomp(b.Depth, G)
+}
+
+// SetCounters sets the counters to update during the search
+func (b *JCostBalSepHybrid) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b JCostBalSepHybrid) GetCounters() Counters {
+	return getCounters(b.counters)
+}
+
+// Name returns the name of the algorithm
+func (b JCostBalSepHybrid) Name() string {
+	return "BalSep / DetK - Hybrid with Depth " + strconv.Itoa(b.Depth+1) + " + Join Optimization"
+}
+
+// orderCovers collects all covers produced by a cover iterator, and orders them by their costs
+func orderCovers(costs lib.CostModel, gen *lib.Cover, bound lib.Edges) [][]int {
+	var covers [][]int
+	var coverCosts []float64
+
+	for gen.HasNext {
+		if gen.NextSubset() == -1 {
+			continue
+		}
+
+		subset := make([]int, len(gen.Subset))
+		copy(subset, gen.Subset)
+		covers = append(covers, subset)
+		coverCosts = append(coverCosts, edgesCost(costs, lib.GetSubset(bound, subset)))
+	}
+
+	order := make([]int, len(covers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return coverCosts[order[i]] < coverCosts[order[j]] })
+
+	output := make([][]int, len(covers))
+	for i, o := range order {
+		output[i] = covers[o]
+	}
+
+	return output
+}
+
+func (b JCostBalSepHybrid) findDecomp(currentDepth int, H lib.Graph) lib.Decomp {
+	level := b.Depth - currentDepth + 1 // the top level is 1, as in DetKDecomp
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return baseCaseSmartCosts(b.Graph, H, b.JCosts)
+	}
+
+	//Early termination
+	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+		return earlyTerminationCosts(H, b.JCosts)
+	}
+
+	var balsep lib.Edges
+
+	//find a balanced separator
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), true)
+	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
+	pred := lib.BalancedCheck{}
+	var ws lib.CompWorkspace
+
+	var cache map[uint32]struct{}
+	cache = make(map[uint32]struct{})
+
+	separators := orderSeparators(b.JCosts, edges, parallelSearch,
+		b.counters.track(level, pred, edges.Len(), b.K, true))
+
+	for _, sep := range separators {
+		balsep = lib.GetSubset(edges, sep.Found)
+
+		var sepSub *lib.SepSub
+		exhaustedSubedges := false
+
+	INNER:
+		for !exhaustedSubedges {
+			comps, _, _ := H.GetComponents(balsep, &ws)
+
+			SepSpecial := lib.NewEdges(balsep.Slice())
+
+			ch := make(chan lib.Decomp)
+			var subtrees []lib.Decomp
+
+			for i := range comps {
+				if currentDepth > 0 {
+					go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
+						comps[i].Special = append(comps[i].Special, SepSpecial)
+						ch <- b.findDecomp(decrease(currentDepth), comps[i])
+					}(i, comps, SepSpecial)
+				} else {
+					go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
+						// Base case handling
+						if comps[i].Len() <= 1 {
+							comps[i].Special = append(comps[i].Special, SepSpecial)
+							ch <- baseCaseSmartCosts(b.Graph, comps[i], b.JCosts)
+							return
+						}
+
+						//Early termination
+						if comps[i].Edges.Len() <= b.K && len(comps[i].Special) == 0 {
+							comps[i].Special = append(comps[i].Special, SepSpecial)
+							ch <- earlyTerminationCosts(comps[i], b.JCosts)
+							return
+						}
+
+						det := DetKDecomp{K: b.K, Graph: b.Graph, BalFactor: b.BalFactor, SubEdge: true,
+							JCosts: b.JCosts, Cancel: cancelOf(b.Generator), counters: b.counters}
+						det.cache.Init()
+
+						result := det.findDecomp(comps[i], balsep.Vertices(), level)
+						if !reflect.DeepEqual(result, lib.Decomp{}) {
+							result.SkipRerooting = true
+						}
+						ch <- result
+					}(i, comps, SepSpecial)
+				}
+			}
+
+			for i := 0; i < len(comps); i++ {
+				decomp := <-ch
+				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+					b.counters.AddBacktrack(level)
+					subtrees = []lib.Decomp{}
+					if sepSub == nil {
+						sepSub = lib.GetSepSub(b.Graph.Edges, balsep, b.K)
+					}
+					nextBalsepFound := false
+				thisLoop:
+					for !nextBalsepFound {
+						if sepSub.HasNext() {
+							balsep = sepSub.GetCurrent()
+							b.counters.AddSubedge()
+							_, ok := cache[lib.IntHash(balsep.Vertices())]
+							if ok { //skip since already seen
+								continue thisLoop
+							}
+
+							if pred.Check(&H, &balsep, b.BalFactor, &ws) {
+								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
+								nextBalsepFound = true
+							}
+						} else {
+							exhaustedSubedges = true
+							continue INNER
+						}
+					}
+					continue INNER
+				}
+
+				subtrees = append(subtrees, decomp)
+			}
+
+			output := newNode(balsep.Vertices(), balsep, edgesCost(b.JCosts, balsep))
+
+			lib.SortDecomps(subtrees) // the subtrees arrive in any order
+			for _, s := range subtrees {
+				if currentDepth == 0 && s.SkipRerooting {
+					// DetKDecomp produces decompositions rooted at a child of the separator already
+				} else {
+					s.Root = s.Root.Reroot(lib.NewNode(balsep.Vertices(), balsep))
+					s.Root = s.Root.Children[0]
+				}
+
+				output.Children = append(output.Children, s.Root)
+			}
+
+			return lib.Decomp{Graph: H, Root: output}
+		}
+	}
+
+	return lib.Decomp{} // empty Decomp signifying reject
+}
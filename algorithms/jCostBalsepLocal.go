@@ -3,10 +3,8 @@ package algorithms
 import (
 	"container/heap"
 	"reflect"
-	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 // BalSepLocal implements the local Balanced Separator algorithm for computing GHDs.
@@ -16,7 +14,8 @@ type JCostBalSepLocal struct {
 	Graph     lib.Graph
 	BalFactor int
 	Generator lib.SearchGenerator
-	JCosts    lib.EdgesCostMap
+	JCosts    lib.CostModel
+	counters  *Counters
 }
 
 // SetGenerator defines the type of Search to use
@@ -29,18 +28,40 @@ func (b *JCostBalSepLocal) SetWidth(K int) {
 	b.K = K
 }
 
+// GetCosts returns the join costs considered by the search
+func (b *JCostBalSepLocal) GetCosts() lib.CostModel {
+	return b.JCosts
+}
+
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *JCostBalSepLocal) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	return &output
+}
+
 func (b JCostBalSepLocal) findGHD(K int) lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1)
 }
 
 // FindDecomp finds a decomp
 func (b JCostBalSepLocal) FindDecomp() lib.Decomp {
-	return b.findDecomp(b.Graph)
+	return b.findDecomp(b.Graph, 1)
 }
 
 // FindDecompGraph finds a decomp, for an explicit graph
 func (b JCostBalSepLocal) FindDecompGraph(G lib.Graph) lib.Decomp {
-	return b.findDecomp(G)
+	return b.findDecomp(G, 1)
+}
+
+// SetCounters sets the counters to update during the search
+func (b *JCostBalSepLocal) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b JCostBalSepLocal) GetCounters() Counters {
+	return getCounters(b.counters)
 }
 
 // Name returns the name of the algorithm
@@ -48,54 +69,60 @@ func (b JCostBalSepLocal) Name() string {
 	return "BalSep Local + Join Optimization"
 }
 
-func baseCaseSmartCosts(g lib.Graph, H lib.Graph, jc lib.EdgesCostMap) lib.Decomp {
+// edgeComb returns the sorted names of the given edges, as used by cost models. Ad-hoc subedges (without a name)
+// are skipped, as they carry no cost of their own.
+func edgeComb(edges lib.Edges) []int {
+	var s []int
+	for _, e := range edges.Slice() {
+		if e.Name != 0 {
+			s = append(s, e.Name)
+		}
+	}
+	return lib.RemoveDuplicates(s)
+}
+
+// edgesCost returns the cost of the combination of the given edges, or 0 if there are none
+func edgesCost(jc lib.CostModel, edges lib.Edges) float64 {
+	s := edgeComb(edges)
+	if len(s) == 0 {
+		return 0
+	}
+	return jc.Cost(s)
+}
+
+func baseCaseSmartCosts(g lib.Graph, H lib.Graph, jc lib.CostModel) lib.Decomp {
 	// log.Printf("Base case reached. Number of Special Edges %d\n", len(Sp))
 	var output lib.Decomp
 
-	var cost float64 = 0
-	if H.Edges.Len() > 0 {
-		s := make([]int, H.Edges.Len())
-		for i, e := range H.Edges.Slice() {
-			s[i] = e.Name
-		}
-		cost = jc.Cost(s)
-	}
+	cost := edgesCost(jc, H.Edges)
 
 	if H.Edges.Len() <= 2 && len(H.Special) == 0 {
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges, Cost: cost}}
+			Root: newNode(H.Vertices(), H.Edges, cost)}
 	} else if H.Edges.Len() == 1 && len(H.Special) == 1 {
 		sp1 := H.Special[0]
 		output = lib.Decomp{Graph: H,
-			Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges, Cost: cost,
-				Children: []lib.Node{lib.Node{Bag: sp1.Vertices(), Cover: sp1}}}}
+			Root: newNode(H.Edges.Vertices(), H.Edges, cost, lib.NewNode(sp1.Vertices(), sp1))}
 	} else {
 		return baseCase(g, H)
 	}
 	return output
 }
 
-func earlyTerminationCosts(H lib.Graph, jc lib.EdgesCostMap) lib.Decomp {
+func earlyTerminationCosts(H lib.Graph, jc lib.CostModel) lib.Decomp {
 	//We assume that H as less than K edges, and only one special edge
-	var cost float64 = 0
-	if H.Edges.Len() > 0 {
-		s := make([]int, H.Edges.Len())
-		for i, e := range H.Edges.Slice() {
-			s[i] = e.Name
-		}
-		cost = jc.Cost(s)
-	}
+	cost := edgesCost(jc, H.Edges)
 
 	return lib.Decomp{Graph: H,
-		Root: lib.Node{Bag: H.Edges.Vertices(), Cover: H.Edges, Cost: cost,
-			Children: []lib.Node{lib.Node{Bag: H.Special[0].Vertices(), Cover: H.Special[0]}}}}
+		Root: newNode(H.Edges.Vertices(), H.Edges, cost, lib.NewNode(H.Special[0].Vertices(), H.Special[0]))}
 }
 
 func rerootingCosts(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp, cost float64) lib.Decomp {
 	//Create a new GHD for H
-	rerootNode := lib.Node{Bag: balsep.Vertices(), Cover: balsep}
-	output := lib.Node{Bag: balsep.Vertices(), Cover: balsep, Cost: cost}
+	rerootNode := lib.NewNode(balsep.Vertices(), balsep)
+	output := newNode(balsep.Vertices(), balsep, cost)
 
+	lib.SortDecomps(subtrees) // the subtrees arrive in any order
 	for _, s := range subtrees {
 		// fmt.Println("H ", H, "balsep ", balsep, "comp ", s.Graph)
 		s.Root = s.Root.Reroot(rerootNode)
@@ -106,7 +133,8 @@ func rerootingCosts(H lib.Graph, balsep lib.Edges, subtrees []lib.Decomp, cost f
 	return lib.Decomp{Graph: H, Root: output}
 }
 
-func orderSeparators(b JCostBalSepLocal, edges lib.Edges, ps lib.Search, pred lib.Predicate) []*lib.Separator {
+// orderSeparators collects all separators found by the search, and orders them by their costs
+func orderSeparators(costs lib.CostModel, edges lib.Edges, ps lib.Search, pred lib.Predicate) []*lib.Separator {
 	var seps [][]int
 	var found []int
 	ps.FindNext(pred) // initial search
@@ -127,11 +155,8 @@ func orderSeparators(b JCostBalSepLocal, edges lib.Edges, ps lib.Search, pred li
 	// populate heap
 	jh := make(lib.JoinHeap, len(seps))
 	for i, fnd := range seps {
-		s := make([]int, len(fnd))
-		for i, f := range fnd {
-			s[i] = edges.Slice()[f].Name
-		}
-		cost := b.JCosts.Cost(s)
+		s := edgeComb(lib.GetSubset(edges, fnd))
+		cost := costs.Cost(s)
 		jh[i] = &lib.Separator{
 			Found:    fnd,
 			EdgeComb: s,
@@ -159,7 +184,7 @@ func orderSeparators(b JCostBalSepLocal, edges lib.Edges, ps lib.Search, pred li
 	return res
 }
 
-func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
+func (b JCostBalSepLocal) findDecomp(H lib.Graph, level int) lib.Decomp {
 	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
 
 	//stop if there are at most two special edges left
@@ -174,16 +199,17 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 	var balsep lib.Edges
 
 	edges := lib.CutEdges(b.Graph.Edges, append(H.Vertices()))
-	generators := lib.SplitCombin(edges.Len(), b.K, runtime.GOMAXPROCS(-1), false)
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), false)
 	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
 	// parallelSearch.FindNext(pred) // initial Search
 
 	var cache map[uint32]struct{}
 	cache = make(map[uint32]struct{})
 
-	separators := orderSeparators(b, edges, parallelSearch, pred)
+	separators := orderSeparators(b.JCosts, edges, parallelSearch,
+		b.counters.track(level, pred, edges.Len(), b.K, false))
 
 	for _, sep := range separators {
 		//for ; !parallelSearch.SearchEnded(); parallelSearch.FindNext(pred) {
@@ -198,7 +224,7 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 
 	INNER:
 		for !exhaustedSubedges {
-			comps, _, _ := H.GetComponents(balsep, Vertices)
+			comps, _, _ := H.GetComponents(balsep, &ws)
 
 			// log.Printf("Comps of Sep: %v for H %v \n", comps, H)
 
@@ -210,13 +236,14 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 			for i := range comps {
 				go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
 					comps[i].Special = append(comps[i].Special, SepSpecial)
-					ch <- b.findDecomp(comps[i])
+					ch <- b.findDecomp(comps[i], level+1)
 				}(i, comps, SepSpecial)
 			}
 
 			for i := 0; i < len(comps); i++ {
 				decomp := <-ch
 				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+					b.counters.AddBacktrack(level)
 					subtrees = []lib.Decomp{}
 					if sepSub == nil {
 						sepSub = lib.GetSepSub(b.Graph.Edges, balsep, b.K)
@@ -226,6 +253,7 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 					for !nextBalsepFound {
 						if sepSub.HasNext() {
 							balsep = sepSub.GetCurrent()
+							b.counters.AddSubedge()
 							if len(balsep.Vertices()) == 0 {
 								continue thisLoop
 							}
@@ -233,7 +261,7 @@ func (b JCostBalSepLocal) findDecomp(H lib.Graph) lib.Decomp {
 							if ok { //skip since already seen
 								continue thisLoop
 							}
-							if pred.Check(&H, &balsep, b.BalFactor, Vertices) {
+							if pred.Check(&H, &balsep, b.BalFactor, &ws) {
 								cache[lib.IntHash(balsep.Vertices())] = lib.Empty
 								nextBalsepFound = true
 							}
@@ -0,0 +1,387 @@
+package algorithms
+
+// Join cost optimal version of BalSep Local, exploring all balanced separators via branch and bound on the total
+// costs of the produced decompositions
+
+import (
+	"log"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// JCostOptBalSepLocal computes a decomposition of minimal total join cost among all decompositions of width ≤ K
+// that can be produced by BalSep Local. Separators are tried in the order of their costs, and any partial
+// decomposition whose cost exceeds the best found so far is pruned. Subedges are only considered for subgraphs
+// that cannot be decomposed otherwise.
+type JCostOptBalSepLocal struct {
+	K         int
+	Graph     lib.Graph
+	BalFactor int
+	Generator lib.SearchGenerator
+	JCosts    lib.CostModel
+	TopN      int // number of alternatives collected by FindDecomp
+	counters  *Counters
+	top       []lib.Decomp
+}
+
+// optResult stores what is known about the cheapest decomposition of a subgraph
+type optResult struct {
+	decomp lib.Decomp
+	cost   float64 // the cost of decomp, or a lower bound on the cost if decomp is empty
+}
+
+// optMemo memoizes the results for subgraphs, shared by all goroutines of one search
+type optMemo struct {
+	mux     sync.Mutex
+	results map[uint64]optResult
+}
+
+// lookup returns the cheapest decomposition of H with cost below bound, if it has been determined already
+func (m *optMemo) lookup(H lib.Graph, bound float64) (lib.Decomp, bool) {
+	m.mux.Lock()
+	res, ok := m.results[H.Hash()]
+	m.mux.Unlock()
+
+	if !ok {
+		return lib.Decomp{}, false
+	}
+	if reflect.DeepEqual(res.decomp, lib.Decomp{}) {
+		if res.cost >= bound {
+			return lib.Decomp{}, true
+		}
+		return lib.Decomp{}, false
+	}
+	if res.cost < bound {
+		return copyDecomp(res.decomp), true
+	}
+	return lib.Decomp{}, true
+}
+
+// store records the outcome of a search for a decomposition of H with cost below bound
+func (m *optMemo) store(H lib.Graph, decomp lib.Decomp, bound float64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if !reflect.DeepEqual(decomp, lib.Decomp{}) {
+		m.results[H.Hash()] = optResult{decomp: copyDecomp(decomp), cost: decomp.TotalCost()}
+		return
+	}
+	if res, ok := m.results[H.Hash()]; !ok || res.cost < bound {
+		m.results[H.Hash()] = optResult{cost: bound}
+	}
+}
+
+// copyDecomp produces a deep copy of the tree of a decomp, so that memoized results can be rerooted safely
+func copyDecomp(decomp lib.Decomp) lib.Decomp {
+	decomp.Root = copyNode(decomp.Root)
+	return decomp
+}
+
+// copyNode copies a subtree, keeping nil slices as they are (rerooting relies on reflect.DeepEqual)
+func copyNode(n lib.Node) lib.Node {
+	var bag []int
+	if n.Bag != nil {
+		bag = make([]int, len(n.Bag))
+		copy(bag, n.Bag)
+	}
+	output := newNode(bag, n.Cover, n.Cost)
+	if n.Children != nil {
+		output.Children = make([]lib.Node, len(n.Children))
+		for i := range n.Children {
+			output.Children[i] = copyNode(n.Children[i])
+		}
+	}
+
+	return output
+}
+
+// SetGenerator defines the type of Search to use
+func (b *JCostOptBalSepLocal) SetGenerator(Gen lib.SearchGenerator) {
+	b.Generator = Gen
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (b *JCostOptBalSepLocal) SetWidth(K int) {
+	b.K = K
+}
+
+// GetCosts returns the join costs considered by the search
+func (b *JCostOptBalSepLocal) GetCosts() lib.CostModel {
+	return b.JCosts
+}
+
+// Copy returns an independent instance of the algorithm, whose search ends once cancel is closed
+func (b *JCostOptBalSepLocal) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := *b
+	output.Generator = lib.CancelSearchGen{Cancel: cancel, Search: b.Generator}
+	output.top = nil
+	return &output
+}
+
+// FindDecomp finds a decomp of minimal cost. If TopN is larger than 1, the alternatives are collected during the
+// same search, see Alternatives.
+func (b *JCostOptBalSepLocal) FindDecomp() lib.Decomp {
+	if b.TopN <= 1 {
+		b.top = nil
+		return b.FindDecompGraph(b.Graph)
+	}
+
+	b.top = b.findTopDecomps(b.Graph)
+	if len(b.top) == 0 {
+		return lib.Decomp{}
+	}
+	return copyDecomp(b.top[0]) // the caller may modify the tree
+}
+
+// Alternatives returns up to TopN decompositions found by the last call of FindDecomp, in the order of their costs.
+// Each decomposition uses a different separator at its root, and is of minimal cost among all decompositions using
+// that separator. The first one is the decomposition returned by FindDecomp.
+func (b *JCostOptBalSepLocal) Alternatives() []lib.Decomp {
+	output := make([]lib.Decomp, len(b.top))
+	for i := range b.top {
+		output[i] = copyDecomp(b.top[i])
+	}
+	return output
+}
+
+// FindDecompGraph finds a decomp of minimal cost, for an explicit graph
+func (b JCostOptBalSepLocal) FindDecompGraph(G lib.Graph) lib.Decomp {
+	memo := &optMemo{results: make(map[uint64]optResult)}
+	return b.findDecomp(G, 1, math.Inf(1), memo)
+}
+
+// SetCounters sets the counters to update during the search
+func (b *JCostOptBalSepLocal) SetCounters(c *Counters) {
+	b.counters = c
+}
+
+// GetCounters returns the counters collected during the search
+func (b JCostOptBalSepLocal) GetCounters() Counters {
+	return getCounters(b.counters)
+}
+
+// Name returns the name of the algorithm
+func (b JCostOptBalSepLocal) Name() string {
+	return "BalSep Local + Optimal Join Cost"
+}
+
+// findTopDecomps returns up to TopN decompositions of H, in the order of their costs
+func (b JCostOptBalSepLocal) findTopDecomps(H lib.Graph) []lib.Decomp {
+	if H.Len() <= 2 || (H.Edges.Len() <= b.K && len(H.Special) == 1) {
+		decomp := b.FindDecompGraph(H)
+		if reflect.DeepEqual(decomp, lib.Decomp{}) {
+			return []lib.Decomp{}
+		}
+		return []lib.Decomp{decomp}
+	}
+
+	memo := &optMemo{results: make(map[uint64]optResult)}
+	var top []lib.Decomp
+
+	b.search(H, 1, math.Inf(1), memo, func(decomp lib.Decomp) float64 {
+		top = append(top, decomp)
+		sort.SliceStable(top, func(i, j int) bool { return top[i].TotalCost() < top[j].TotalCost() })
+		if len(top) < b.TopN {
+			return math.Inf(1)
+		}
+		top = top[:b.TopN]
+		return top[b.TopN-1].TotalCost()
+	})
+
+	return top
+}
+
+// withinBound rejects a decomposition if its cost is not below the bound
+func withinBound(decomp lib.Decomp, bound float64) lib.Decomp {
+	if reflect.DeepEqual(decomp, lib.Decomp{}) || decomp.TotalCost() >= bound {
+		return lib.Decomp{}
+	}
+	return decomp
+}
+
+// findDecomp returns a decomp of H of minimal cost, if its cost is below the bound
+func (b JCostOptBalSepLocal) findDecomp(H lib.Graph, level int, bound float64, memo *optMemo) lib.Decomp {
+	//stop if there are at most two special edges left
+	if H.Len() <= 2 {
+		return withinBound(baseCaseSmartCosts(b.Graph, H, b.JCosts), bound)
+	}
+
+	//Early termination
+	if H.Edges.Len() <= b.K && len(H.Special) == 1 {
+		return withinBound(earlyTerminationCosts(H, b.JCosts), bound)
+	}
+
+	decomp, ok := memo.lookup(H, bound)
+	b.counters.AddCacheLookup(ok)
+	if ok {
+		return decomp
+	}
+
+	var best lib.Decomp
+	b.search(H, level, bound, memo, func(decomp lib.Decomp) float64 {
+		best = decomp
+		return decomp.TotalCost()
+	})
+
+	memo.store(H, best, bound)
+
+	return best
+}
+
+// search explores the balanced separators of H in the order of their costs, passing each decomposition with cost
+// below the current bound to visit, which returns the new bound
+func (b JCostOptBalSepLocal) search(H lib.Graph, level int, bound float64, memo *optMemo,
+	visit func(lib.Decomp) float64) {
+	edges := lib.CutEdges(b.Graph.Edges, H.Vertices())
+	generators := lib.SplitCombin(edges.Len(), b.K, lib.Workers(b.Generator), false)
+	parallelSearch := b.Generator.GetSearch(&H, &edges, b.BalFactor, generators)
+	pred := lib.BalancedCheck{}
+
+	separators := orderSeparators(b.JCosts, edges, parallelSearch,
+		b.counters.track(level, pred, edges.Len(), b.K, false))
+
+	found := false
+	for _, sep := range separators {
+		if sep.Cost >= bound {
+			break // all remaining separators are at least as expensive
+		}
+
+		decomp := b.decompWithSep(H, level, lib.GetSubset(edges, sep.Found), sep.Cost, bound, memo)
+		if !reflect.DeepEqual(decomp, lib.Decomp{}) {
+			found = true
+			bound = visit(decomp)
+		}
+	}
+
+	if found {
+		return
+	}
+
+	// fall back to subedges
+	var ws lib.CompWorkspace
+	cache := make(map[uint32]struct{})
+
+	for _, sep := range separators {
+		if sep.Cost >= bound {
+			break
+		}
+
+		sepSub := lib.GetSepSub(b.Graph.Edges, lib.GetSubset(edges, sep.Found), b.K)
+		for sepSub.HasNext() {
+			balsep := sepSub.GetCurrent()
+			b.counters.AddSubedge()
+			if len(balsep.Vertices()) == 0 {
+				continue
+			}
+			if _, ok := cache[lib.IntHash(balsep.Vertices())]; ok { //skip since already seen
+				continue
+			}
+			cache[lib.IntHash(balsep.Vertices())] = lib.Empty
+
+			if !pred.Check(&H, &balsep, b.BalFactor, &ws) {
+				continue
+			}
+
+			cost := edgesCost(b.JCosts, balsep)
+			if cost >= bound {
+				continue
+			}
+
+			decomp := b.decompWithSep(H, level, balsep, cost, bound, memo)
+			if !reflect.DeepEqual(decomp, lib.Decomp{}) {
+				bound = visit(decomp)
+			}
+		}
+	}
+}
+
+// decompWithSep produces the cheapest decomposition of H using balsep at the root, if its cost is below the bound
+func (b JCostOptBalSepLocal) decompWithSep(H lib.Graph, level int, balsep lib.Edges, sepCost float64, bound float64,
+	memo *optMemo) lib.Decomp {
+	var ws lib.CompWorkspace
+	comps, _, _ := H.GetComponents(balsep, &ws)
+
+	SepSpecial := lib.NewEdges(balsep.Slice())
+
+	subtrees := make([]lib.Decomp, len(comps))
+	var wg sync.WaitGroup
+	wg.Add(len(comps))
+
+	// the components are independent, so each of them only needs to fit into the remaining budget on its own
+	for i := range comps {
+		go func(i int, comps []lib.Graph, SepSpecial lib.Edges) {
+			defer wg.Done()
+			comps[i].Special = append(comps[i].Special, SepSpecial)
+			subtrees[i] = b.findDecomp(comps[i], level+1, bound-sepCost, memo)
+		}(i, comps, SepSpecial)
+	}
+	wg.Wait()
+
+	for i := range subtrees {
+		if reflect.DeepEqual(subtrees[i], lib.Decomp{}) {
+			b.counters.AddBacktrack(level)
+			return lib.Decomp{}
+		}
+	}
+
+	return withinBound(combineAtSep(H, balsep, SepSpecial, subtrees, sepCost), bound)
+}
+
+// combineAtSep creates a decomp of H with balsep at the root, attaching the subtrees for each component via the node
+// covered by the special edge SepSpecial
+func combineAtSep(H lib.Graph, balsep lib.Edges, SepSpecial lib.Edges, subtrees []lib.Decomp, cost float64) lib.Decomp {
+	output := newNode(balsep.Vertices(), balsep, cost)
+	for _, s := range subtrees {
+		root, ok := rerootAtSep(s.Root, SepSpecial)
+		if !ok {
+			log.Panicf("Can't reroot: no node for separator %v in %v\n", SepSpecial, s.Root)
+		}
+		output.Children = append(output.Children, root.Children...)
+	}
+
+	return lib.Decomp{Graph: H, Root: output}
+}
+
+// rerootAtSep reroots a subtree at the node covered by the separator sep. Unlike Node.Reroot, the node is identified
+// by its contents, since memoized subtrees stem from different (but equal) separators.
+func rerootAtSep(n lib.Node, sep lib.Edges) (lib.Node, bool) {
+	path, ok := pathToSep(n, sep)
+	if !ok {
+		return lib.Node{}, false
+	}
+
+	for _, i := range path {
+		child := n.Children[i]
+
+		var rest []lib.Node
+		rest = append(rest, n.Children[:i]...)
+		rest = append(rest, n.Children[i+1:]...)
+		n.Children = rest
+
+		var children []lib.Node
+		children = append(children, child.Children...)
+		child.Children = append(children, n)
+		n = child
+	}
+
+	return n, true
+}
+
+// pathToSep returns the positions of the children leading from n to the node covered by sep
+func pathToSep(n lib.Node, sep lib.Edges) ([]int, bool) {
+	if n.Cover.Hash() == sep.Hash() && lib.Subset(n.Bag, sep.Vertices()) && lib.Subset(sep.Vertices(), n.Bag) {
+		return []int{}, true
+	}
+
+	for i := range n.Children {
+		if path, ok := pathToSep(n.Children[i], sep); ok {
+			return append([]int{i}, path...), true
+		}
+	}
+
+	return []int{}, false
+}
@@ -0,0 +1,175 @@
+package algorithms
+
+// portfolio.go implements an algorithm racing several other algorithms against each other
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// cancelOf returns the cancellation channel of a search generator, or nil if it cannot be cancelled
+func cancelOf(gen lib.SearchGenerator) <-chan struct{} {
+	if c, ok := gen.(lib.CancelSearchGen); ok {
+		return c.Cancel
+	}
+	return nil
+}
+
+// A PortfolioMember is one of the algorithms raced by a Portfolio
+type PortfolioMember struct {
+	Algorithm Algorithm
+	Complete  bool // if true, a rejection by the algorithm proves that no decomposition of width K exists
+}
+
+// Portfolio runs several algorithms concurrently on the same graph and width, sharing the available CPUs. The
+// first decomposition found, or the first rejection by a complete algorithm, is returned, and all other algorithms
+// are cancelled. The name of the algorithm that produced the result is stored in Winner.
+//
+// Since the members are reconfigured for each run, a Portfolio must not be used by several goroutines at once.
+type Portfolio struct {
+	K        int
+	Graph    lib.Graph
+	Members  []PortfolioMember
+	Winner   string          // name of the algorithm that decided the last run, or empty if none could
+	cancel   <-chan struct{} // if closed, all members are cancelled and the run rejected
+	workers  int             // the number of goroutines divided among the members, GOMAXPROCS if 0
+	counters *Counters
+}
+
+// portfolioResult is the outcome of a single member
+type portfolioResult struct {
+	member int
+	decomp lib.Decomp
+}
+
+// SetGenerator only takes over a cancellation and the number of goroutines, as each member is given its own
+// cancellable search
+func (p *Portfolio) SetGenerator(Gen lib.SearchGenerator) {
+	p.cancel = cancelOf(Gen)
+	p.workers = lib.Workers(Gen)
+}
+
+// SetWidth sets the current width parameter of the algorithm
+func (p *Portfolio) SetWidth(K int) {
+	p.K = K
+}
+
+// Copy returns an independent instance of the algorithm, with copies of all members, whose run ends once cancel is
+// closed. Members which can't be copied are shared.
+func (p *Portfolio) Copy(cancel <-chan struct{}) lib.AlgorithmH {
+	output := &Portfolio{K: p.K, Graph: p.Graph, cancel: cancel, workers: p.workers, counters: p.counters}
+	for _, m := range p.Members {
+		if c, ok := m.Algorithm.(lib.CopyableAlgorithm); ok {
+			if alg, ok := c.Copy(nil).(Algorithm); ok {
+				m.Algorithm = alg
+			}
+		}
+		output.Members = append(output.Members, m)
+	}
+	return output
+}
+
+// SetCounters sets the counters to update during the search, shared by all members which support them
+func (p *Portfolio) SetCounters(c *Counters) {
+	p.counters = c
+	for _, m := range p.Members {
+		if debug, ok := m.Algorithm.(AlgorithmDebug); ok {
+			debug.SetCounters(c)
+		}
+	}
+}
+
+// GetCounters returns the counters collected during the search
+func (p *Portfolio) GetCounters() Counters {
+	return getCounters(p.counters)
+}
+
+// Name returns the name of the algorithm
+func (p *Portfolio) Name() string {
+	var names []string
+	for _, m := range p.Members {
+		names = append(names, m.Algorithm.Name())
+	}
+	return "Portfolio (" + strings.Join(names, ", ") + ")"
+}
+
+// FindDecomp finds a decomp
+func (p *Portfolio) FindDecomp() lib.Decomp {
+	return p.FindDecompGraph(p.Graph)
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (p *Portfolio) FindDecompGraph(G lib.Graph) lib.Decomp {
+	p.Winner = ""
+	if len(p.Members) == 0 {
+		return lib.Decomp{}
+	}
+
+	cancel := make(chan struct{})
+	results := make(chan portfolioResult, len(p.Members))
+
+	var once sync.Once
+	stop := func() { once.Do(func() { close(cancel) }) }
+	if p.cancel != nil { // pass a cancellation of the whole run on to the members
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-p.cancel:
+				stop()
+			case <-done:
+			}
+		}()
+	}
+
+	// the goroutines are divided among the members, each getting at least one
+	workers := p.workers
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(-1)
+	}
+
+	for i := range p.Members {
+		share := workers / len(p.Members)
+		if i < workers%len(p.Members) {
+			share++
+		}
+		if share == 0 {
+			share = 1
+		}
+
+		alg := p.Members[i].Algorithm
+		alg.SetWidth(p.K)
+		alg.SetGenerator(lib.CancelSearchGen{Cancel: cancel, Workers: share})
+
+		go func(i int, alg Algorithm) {
+			results <- portfolioResult{member: i, decomp: alg.FindDecompGraph(G)}
+		}(i, alg)
+	}
+
+	var output lib.Decomp
+	decided := false
+	for received := 0; received < len(p.Members); received++ {
+		res := <-results
+		if decided {
+			continue // wait for the cancelled members to finish, so they can be reused
+		}
+
+		rejected := reflect.DeepEqual(res.decomp, lib.Decomp{})
+		if !rejected || p.Members[res.member].Complete {
+			decided = true
+			output = res.decomp
+			p.Winner = p.Members[res.member].Algorithm.Name()
+			stop()
+		}
+	}
+
+	if !decided {
+		stop()
+	}
+
+	return output
+}
@@ -34,11 +34,11 @@ func (d *SplitDecomp) FindDecompGraph(G lib.Graph) lib.Decomp {
 	}
 
 	rootCover := lib.NewEdges(G.Edges.Slice()[:d.K])
-	root := lib.Node{Bag: rootCover.Vertices(), Cover: rootCover}
+	root := lib.NewNode(rootCover.Vertices(), rootCover)
 
 	if G.Edges.Len() > d.K {
 		childCover := lib.NewEdges(G.Edges.Slice()[d.K:])
-		child := lib.Node{Bag: childCover.Vertices(), Cover: childCover}
+		child := lib.NewNode(childCover.Vertices(), childCover)
 		root.Children = []lib.Node{child}
 	}
 
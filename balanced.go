@@ -28,6 +28,8 @@ import (
 	"runtime/pprof"
 	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -65,13 +67,13 @@ func check(e error) {
 
 }
 
-//Version indicates the version exported from the Git repository
+// Version indicates the version exported from the Git repository
 var Version string
 
-//Date indicates the build date exported from the Git repository
+// Date indicates the build date exported from the Git repository
 var Date string
 
-//Build indicates the exact build when current version was compiled
+// Build indicates the exact build when current version was compiled
 var Build string
 
 type labelTime struct {
@@ -83,11 +85,104 @@ func (l labelTime) String() string {
 	return fmt.Sprintf("%s : %.5f ms", l.label, l.time)
 }
 
-func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Graph, gml string, json string, K int, skipCheck bool) {
+// jsonTime is a labelTime in the JSON output
+type jsonTime struct {
+	Label string  `json:"label"`
+	Time  float64 `json:"time"`
+}
+
+// jsonHinge is a lib.HingeStat in the JSON output
+type jsonHinge struct {
+	Edges     int     `json:"edges"`
+	Algorithm string  `json:"algorithm"`
+	Width     int     `json:"width"`
+	Time      float64 `json:"time"`
+	Solved    bool    `json:"solved"`
+}
+
+// preprocessingStats summarises the effect of the preprocessing steps on the graph
+type preprocessingStats struct {
+	TypeCollapse int `json:"typeCollapse"` // number of vertices removed by the type collapse
+	GYÖOps       int `json:"gyoOps"`       // number of operations of the GYÖ reduct
+	HingeTree    int `json:"hingeTree"`    // number of hinges in the hingetree
+	Blocks       int `json:"blocks"`       // number of blocks when splitting at articulation vertices
+	SafeSepParts int `json:"safeSepParts"` // number of parts when splitting at safe separators
+	Edges        int `json:"edges"`        // number of edges after preprocessing
+	Vertices     int `json:"vertices"`     // number of vertices after preprocessing
+}
+
+// jsonOutput is the document written by the JSON output format, with all times given in ms
+type jsonOutput struct {
+	Algorithm     string             `json:"algorithm"`
+	Version       string             `json:"version"`
+	Build         string             `json:"build"`
+	Graph         string             `json:"graph"`
+	Seed          int64              `json:"seed"`
+	K             int                `json:"k"`
+	Width         int                `json:"width"`
+	Correct       bool               `json:"correct"`
+	TotalCost     float64            `json:"totalCost,omitempty"`
+	Time          float64            `json:"time"`
+	Times         []jsonTime         `json:"times"`
+	Preprocessing preprocessingStats `json:"preprocessing"`
+	Hinges        []jsonHinge        `json:"hinges,omitempty"`
+	Winner        string             `json:"winner,omitempty"`
+	Counters      map[string]int64   `json:"counters"`
+	Decomp        *lib.DecompJson    `json:"decomp"` // null if no correct decomposition was found
+}
+
+// writeDecompFiles writes a correct decomposition into the gml and json files, if they are given
+func writeDecompFiles(decomp Decomp, gml string, json string) {
+	if len(gml) > 0 {
+		f, err := os.Create(gml)
+		check(err)
+
+		defer f.Close()
+		f.WriteString(decomp.ToGML())
+		f.Sync()
+	}
+	if len(json) > 0 {
+		f, err := os.Create(json)
+		check(err)
+
+		defer f.Close()
+		f.Write(lib.WriteDecomp(decomp))
+		f.Sync()
+	}
+}
+
+// outputJSON completes the JSON output with the result and writes it to out
+func outputJSON(out io.Writer, doc jsonOutput, decomp Decomp, times []labelTime, graph Graph, gml string,
+	jsonPath string) {
+	decomp.RestoreSubedges()
+
+	doc.Seed = lib.Seed()
+	doc.Width = decomp.CheckWidth()
+	doc.TotalCost = decomp.TotalCost()
+	doc.Times = []jsonTime{}
+	for _, time := range times {
+		doc.Time = doc.Time + time.time
+		doc.Times = append(doc.Times, jsonTime{Label: time.label, Time: time.time})
+	}
+
+	doc.Correct = decomp.Correct(graph)
+	if doc.Correct {
+		decompJSON := decomp.IntoJson()
+		doc.Decomp = &decompJSON
+		writeDecompFiles(decomp, gml, jsonPath)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	check(err)
+	out.Write(append(data, '\n'))
+}
+
+func outputStanza(out io.Writer, algorithm string, decomp Decomp, times []labelTime, graph Graph, gml string, json string, K int, skipCheck bool) {
 	decomp.RestoreSubedges()
 
-	fmt.Println("Used algorithm: " + algorithm + " @" + Version)
-	fmt.Println("Result ( ran with K =", K, ")\n", decomp)
+	fmt.Fprintln(out, "Used algorithm: "+algorithm+" @"+Version)
+	fmt.Fprintln(out, "Seed: ", lib.Seed())
+	fmt.Fprintln(out, "Result ( ran with K =", K, ")\n", decomp)
 
 	// Print the times
 	var sumTotal float64
@@ -95,14 +190,17 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 	for _, time := range times {
 		sumTotal = sumTotal + time.time
 	}
-	fmt.Printf("Time: %.5f ms\n", sumTotal)
+	fmt.Fprintf(out, "Time: %.5f ms\n", sumTotal)
 
-	fmt.Println("Time Composition: ")
+	fmt.Fprintln(out, "Time Composition: ")
 	for _, time := range times {
-		fmt.Println(time)
+		fmt.Fprintln(out, time)
 	}
 
-	fmt.Println("\nWidth: ", decomp.CheckWidth())
+	fmt.Fprintln(out, "\nWidth: ", decomp.CheckWidth())
+	if cost := decomp.TotalCost(); cost != 0 {
+		fmt.Fprintf(out, "Total cost: %.2f\n", cost)
+	}
 	var correct bool
 	if !skipCheck {
 		correct = decomp.Correct(graph)
@@ -110,22 +208,9 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 		correct = true
 	}
 
-	fmt.Println("Correct: ", correct)
-	if correct && len(gml) > 0 {
-		f, err := os.Create(gml)
-		check(err)
-
-		defer f.Close()
-		f.WriteString(decomp.ToGML())
-		f.Sync()
-	}
-	if correct && len(json) > 0 {
-		f, err := os.Create(json)
-		check(err)
-
-		defer f.Close()
-		f.Write(lib.WriteDecomp(decomp))
-		f.Sync()
+	fmt.Fprintln(out, "Correct: ", correct)
+	if correct {
+		writeDecompFiles(decomp, gml, json)
 	}
 }
 
@@ -134,6 +219,152 @@ func outputShellio(decomp Decomp) {
 	os.Stdout.Write(lib.WriteDecomp(decomp))
 }
 
+// progressReport is one of the periodic reports on the progress of the search, with the time given in ms
+type progressReport struct {
+	Time         float64     `json:"time"`
+	Width        int64       `json:"width"`               // the width currently searched for
+	BestWidth    int64       `json:"bestWidth,omitempty"` // the width of the best decomposition so far, in approx mode
+	Completion   float64     `json:"completion"`          // share of the top-level search space checked, -1 if unknown
+	Backtracks   map[int]int `json:"backtracks"`          // backtracks per level, starting with 1 for the top level
+	CacheLookups int64       `json:"cacheLookups"`
+	CacheHits    int64       `json:"cacheHits"`
+}
+
+func (p progressReport) String() string {
+	var buffer strings.Builder
+
+	fmt.Fprintf(&buffer, "[%.0f ms] width %d", p.Time, p.Width)
+	if p.BestWidth > 0 {
+		fmt.Fprintf(&buffer, ", best width %d", p.BestWidth)
+	}
+	if p.Completion >= 0 {
+		fmt.Fprintf(&buffer, ", toplevel completion %.2f%%", p.Completion*100)
+	}
+
+	var levels []int
+	for level := range p.Backtracks {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	var backtracks []string
+	for _, level := range levels {
+		backtracks = append(backtracks, fmt.Sprintf("%d: %d", level, p.Backtracks[level]))
+	}
+	fmt.Fprintf(&buffer, ", backtracks by level {%s}", strings.Join(backtracks, ", "))
+
+	if p.CacheLookups > 0 {
+		fmt.Fprintf(&buffer, ", cache hit rate %.2f%%", float64(p.CacheHits)/float64(p.CacheLookups)*100)
+	}
+
+	return buffer.String()
+}
+
+// reportProgress writes a report at each interval until stop is closed, either as text or as JSON lines
+func reportProgress(w io.Writer, asJSON bool, interval time.Duration, stop <-chan struct{},
+	report func() progressReport) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	encoder := json.NewEncoder(w)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if asJSON {
+				encoder.Encode(report())
+			} else {
+				fmt.Fprintln(w, report())
+			}
+		}
+	}
+}
+
+// loadJoinCosts reads the costs of edge combinations from a CSV file, where each line lists the names of the edges,
+// followed by their cost
+func loadJoinCosts(path string, encoding map[string]int) (*lib.EdgesCostMap, error) {
+	csvfile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer csvfile.Close()
+
+	var w lib.EdgesCostMap
+	w.Init()
+
+	r := csv.NewReader(csvfile)
+	r.FieldsPerRecord = -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// put the record into the map
+		last := len(record) - 1
+		cost, _ := strconv.ParseFloat(record[last], 64)
+		rec := record[:last]
+		comb := make([]int, len(rec))
+		for p, s := range rec {
+			comb[p] = encoding[s]
+		}
+		sort.Ints(comb)
+		w.Put(comb, cost)
+	}
+
+	return &w, nil
+}
+
+// portfolioMembers creates the algorithms of a portfolio from a comma-separated list of their names, where the
+// hybrid algorithms are followed by their depth, e.g. "local,det,balDet:2"
+func portfolioMembers(spec string, graph Graph, K int, BalFactor int) ([]algo.PortfolioMember, error) {
+	var members []algo.PortfolioMember
+
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		depth := 1
+		if i := strings.Index(name, ":"); i != -1 {
+			var err error
+			depth, err = strconv.Atoi(name[i+1:])
+			if err != nil || depth < 1 {
+				return nil, fmt.Errorf("invalid depth in %v", name)
+			}
+			name = name[:i]
+		}
+
+		switch name {
+		case "local": // the only one which is complete for GHDs on graphs without added subedges
+			members = append(members, algo.PortfolioMember{
+				Algorithm: &algo.BalSepLocal{K: K, Graph: graph, BalFactor: BalFactor},
+				Complete:  true,
+			})
+		case "global":
+			members = append(members, algo.PortfolioMember{
+				Algorithm: &algo.BalSepGlobal{K: K, Graph: graph, BalFactor: BalFactor},
+			})
+		case "det":
+			members = append(members, algo.PortfolioMember{
+				Algorithm: &algo.DetKDecomp{K: K, Graph: graph, BalFactor: BalFactor},
+			})
+		case "balDet":
+			members = append(members, algo.PortfolioMember{
+				Algorithm: &algo.BalSepHybrid{K: K, Graph: graph, BalFactor: BalFactor, Depth: depth - 1},
+			})
+		case "seqBalDet":
+			members = append(members, algo.PortfolioMember{
+				Algorithm: &algo.BalSepHybridSeq{K: K, Graph: graph, BalFactor: BalFactor, Depth: depth - 1},
+			})
+		default:
+			return nil, fmt.Errorf("unknown algorithm %v", name)
+		}
+	}
+
+	return members, nil
+}
+
 func main() {
 
 	// ==============================================
@@ -155,13 +386,23 @@ func main() {
 	localBIP := flagSet.Bool("localbip", false, "Used in combination with \"det\": turns on local subedge handling")
 	balDetFlag := flagSet.Int("balDet", 0, "Use the Hybrid BalSep-DetK algorithm. Number indicates depth, must be ≥ 1")
 	seqBalDetFlag := flagSet.Int("seqBalDet", 0, "Use sequential Hybrid BalSep - DetK algorithm.")
+	portfolioFlag := flagSet.String("portfolio", "", "Race several algorithms, given as a comma-separated list of: local, global,\n\tdet, balDet:<depth>, seqBalDet:<depth>. The first to find a decomposition wins, only local can reject")
+	hingePortfolioFlag := flagSet.Int("hingePortfolio", 0, "Decompose each hinge with the algorithm best suited for it: acyclic hinges directly, hinges\n\twith at most this many edges with DetK, and larger ones with the Hybrid BalSep-DetK algorithm")
+	portfolioDepth := flagSet.Int("portfolioDepth", 1, "Used in combination with \"hingePortfolio\": the depth of the Hybrid BalSep-DetK algorithm, must be ≥ 1")
+
+	autoFlag := flagSet.Bool("auto", false, "Choose algorithm, heuristic and depth automatically, based on the features of the graph")
+	autoRules := flagSet.String("autoRules", "", "Used in combination with \"auto\": path to a decision tree in JSON, replacing the default one, which is a\n\thand-written placeholder")
 
 	// heuristic flags
-	heur := "1 ... Vertex Degree Ordering\n\t2 ... Max. Separator Ordering\n\t3 ... MCSO\n\t4 ... Edge Degree Ordering"
+	heur := "1 ... Vertex Degree Ordering\n\t2 ... Max. Separator Ordering\n\t3 ... MCSO\n\t4 ... Edge Degree Ordering" +
+		"\n\t5 ... Min-Fill Elimination Ordering\n\t6 ... Min-Degree Elimination Ordering\n\t7 ... MCS-M Elimination Ordering"
 	useHeuristic := flagSet.Int("heuristic", 0, "turn on to activate edge ordering\n\t"+heur)
+	restarts := flagSet.Int("restarts", 0, "Used in combination with heuristics 5-7: number of randomized restarts, keeping the ordering of least width")
 	gyö := flagSet.Bool("g", false, "perform a GYÖ reduct")
 	typeC := flagSet.Bool("t", false, "perform a Type Collapse")
 	hingeFlag := flagSet.Bool("h", false, "use hingeTree Optimization")
+	blocksFlag := flagSet.Bool("blocks", false, "Split the graph at articulation vertices, and decompose each block in parallel")
+	safeFlag := flagSet.Bool("safe", false, "Split the graph at clique minimal separators, and decompose each part in parallel")
 
 	//other optional  flags
 	cpuprofile := flagSet.String("cpuprofile", "", "write cpu profile to file")
@@ -169,13 +410,25 @@ func main() {
 	computeSubedges := flagSet.Bool("sub", false, "turn off subedge computation for global option")
 	balanceFactorFlag := flagSet.Int("balfactor", 2, "Changes the factor that balanced separator check uses, default 2")
 	numCPUs := flagSet.Int("cpu", -1, "Set number of CPUs to use")
+	seedFlag := flagSet.Int64("seed", 0, "Seed for all randomized heuristics. If set, the search is made deterministic, so that the same\n\tseed and number of CPUs reproduce the same decomposition. Not supported by the portfolio")
+	format := flagSet.String("format", "text", "Output format of the result: text, or json for a single JSON document on standard output,\n\twith all other output moved to standard error")
+	progress := flagSet.Duration("progress", 0, "Report the progress of the search to standard error at this interval, e.g. 5s")
+	progressFile := flagSet.String("progressFile", "", "Used in combination with \"progress\": write the reports as JSON lines into the specified file")
+	tracePath := flagSet.String("trace", "", "Record each recursion step of the local BalSep algorithm into the specified file as JSON lines,\n\tcompressed if the file name ends in .gz (see tools/TraceView)")
+	metricsAddr := flagSet.String("metrics", "", "Serve metrics of the search in Prometheus text format on this address, e.g. :9090, under /metrics")
+	acyclicityFlag := flagSet.Bool("acyclicity", false, "Report which notions of acyclicity (α, β, γ) the input graph satisfies")
 	bench := flagSet.Bool("bench", false, "Benchmark mode, reduces unneeded output (incompatible with -log flag)")
 	gml := flagSet.String("gml", "", "Output the produced decomposition into the specified gml file ")
 	shellio := flagSet.Bool("shellio", false, "Output the produced decomposition into the specified gml file ")
 	jsonFlag := flagSet.String("json", "", "Output the produced decomposition into the specified json file ")
 	pace := flagSet.Bool("pace", false, "Use PACE 2019 format for graphs (see pacechallenge.org/2019/htd/htd_format/)")
+	xcsp := flagSet.Bool("xcsp", false, "Use XCSP3 format for CSP instances (see xcsp.org), using constraint scopes as edges")
 	complete := flagSet.Bool("complete", false, "Forces the computation of complete decompositions.")
 	jCostPath := flagSet.String("joinCost", "", "The file path to a join cost function.")
+	jStatsPath := flagSet.String("joinStats", "", "The file path to relation statistics (JSON), used to estimate join costs.")
+	optCost := flagSet.Bool("optCost", false, "Search for a decomposition of minimal join cost (requires local and a join cost)")
+	enumFlag := flagSet.Int("enum", 0, "Enumerate up to N distinct decompositions of the given width, using BalSep Local")
+	topN := flagSet.Int("topN", 0, "Also output the N cheapest decompositions with distinct root separators (requires optCost)")
 
 	parseError := flagSet.Parse(os.Args[1:])
 	if parseError != nil {
@@ -225,11 +478,45 @@ func main() {
 		return
 	}
 
-	if *shellio && (*jsonFlag != "" || *gml != "" || *graphPath != "" ) {
+	if *shellio && (*jsonFlag != "" || *gml != "" || *graphPath != "") {
 		fmt.Println("Output and input files are not supported in Shell I/O mode")
 		return
 	}
 
+	if *pace && *xcsp {
+		fmt.Println("Cannot use PACE and XCSP3 formats at the same time. Make up your mind.")
+		return
+	}
+
+	if *format != "text" && *format != "json" {
+		fmt.Println("Unknown output format: ", *format)
+		return
+	}
+
+	if *format == "json" && (*shellio || *enumFlag > 0) {
+		fmt.Println("JSON output is not supported in Shell I/O mode or for enumeration")
+		return
+	}
+
+	if (*optCost || *topN > 0) && *jCostPath == "" && *jStatsPath == "" {
+		fmt.Println("Optimal join cost and alternatives require a join cost function or relation statistics.")
+		return
+	}
+
+	if *topN > 0 && (!*optCost || *hingeFlag || *blocksFlag || *safeFlag) {
+		fmt.Println("Alternatives can be used only in combination with optCost, and not with: h, blocks, safe.")
+		return
+	}
+
+	// keep standard output for the JSON document, anything else printed goes to standard error
+	out := os.Stdout
+	var info io.Writer = os.Stdout
+	if *format == "json" {
+		info = os.Stderr
+		lib.SetOutput(os.Stderr)
+	}
+	doc := jsonOutput{Version: Version, Build: Build, Graph: *graphPath, Counters: make(map[string]int64)}
+
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
 		if err != nil {
@@ -249,6 +536,17 @@ func main() {
 
 	runtime.GOMAXPROCS(*numCPUs)
 
+	// only an explicitly given seed makes the run reproducible
+	seeded := false
+	flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seeded = true
+		}
+	})
+	if seeded {
+		lib.SetSeed(*seedFlag)
+	}
+
 	var dat []byte
 	var err error
 
@@ -262,14 +560,58 @@ func main() {
 	var parsedGraph Graph
 	var parseGraph lib.ParseGraph
 
-	if !*pace {
-		parsedGraph, parseGraph = lib.GetGraph(string(dat))
-	} else {
+	switch {
+	case *pace:
 		parsedGraph = lib.GetGraphPACE(string(dat))
+	case *xcsp:
+		parsedGraph, parseGraph = lib.GetGraphXCSP(string(dat))
+	default:
+		parsedGraph, parseGraph = lib.GetGraph(string(dat))
 	}
 
 	originalGraph := parsedGraph
 
+	if *autoFlag {
+		if *localBal || *globalBal || *detKFlag || *balDetFlag > 0 || *seqBalDetFlag > 0 || *portfolioFlag != "" ||
+			*hingePortfolioFlag > 0 || *useHeuristic > 0 {
+			fmt.Fprintln(info, "Auto mode chooses the algorithm and heuristic, cannot be combined with explicit choices.")
+			return
+		}
+
+		rules := []byte(lib.DefaultDecisionTree)
+		if *autoRules != "" {
+			rules, err = ioutil.ReadFile(*autoRules)
+			check(err)
+		}
+		tree, err := lib.GetDecisionTree(rules)
+		if err != nil {
+			fmt.Fprintln(info, "Invalid decision tree: ", err)
+			return
+		}
+
+		features := lib.GetFeatures(parsedGraph)
+		choice := tree.Decide(features)
+
+		switch choice.Algorithm {
+		case "local":
+			*localBal = true
+		case "global":
+			*globalBal = true
+		case "det":
+			*detKFlag = true
+		case "balDet":
+			*balDetFlag = choice.Depth
+		case "seqBalDet":
+			*seqBalDetFlag = choice.Depth
+		}
+		*useHeuristic = choice.Heuristic
+
+		if !*bench {
+			fmt.Fprintln(info, "Features: ", features)
+			fmt.Fprintln(info, "Chosen automatically: ", choice)
+		}
+	}
+
 	if !*bench { // skip any output if bench flag is set
 		log.Println("BIP: ", parsedGraph.GetBIP())
 	}
@@ -300,15 +642,27 @@ func main() {
 			parsedGraph.Edges = lib.GetEdgeDegreeOrder(parsedGraph.Edges)
 			heuristicMessage = "Using edge degree ordering as a heuristic"
 			break
+		case 5:
+			parsedGraph.Edges = lib.GetMinFillOrder(parsedGraph.Edges, *restarts)
+			heuristicMessage = "Using min-fill elimination ordering as a heuristic"
+			break
+		case 6:
+			parsedGraph.Edges = lib.GetMinDegreeOrder(parsedGraph.Edges, *restarts)
+			heuristicMessage = "Using min-degree elimination ordering as a heuristic"
+			break
+		case 7:
+			parsedGraph.Edges = lib.GetMCSMOrder(parsedGraph.Edges, *restarts)
+			heuristicMessage = "Using MCS-M elimination ordering as a heuristic"
+			break
 		}
 		d := time.Now().Sub(start)
 		msec := d.Seconds() * float64(time.Second/time.Millisecond)
 		times = append(times, labelTime{time: msec, label: "Heuristic"})
 
 		if !*bench && !*shellio {
-			fmt.Println(heuristicMessage)
-			fmt.Printf("Time for heuristic: %.5f ms\n", msec)
-			fmt.Printf("Ordering: %v\n", parsedGraph.String())
+			fmt.Fprintln(info, heuristicMessage)
+			fmt.Fprintf(info, "Time for heuristic: %.5f ms\n", msec)
+			fmt.Fprintf(info, "Ordering: %v\n", parsedGraph.String())
 		}
 	}
 	var removalMap map[int][]int
@@ -316,14 +670,15 @@ func main() {
 	if *typeC {
 		count := 0
 		reducedGraph, removalMap, count = parsedGraph.TypeCollapse()
+		doc.Preprocessing.TypeCollapse = count
 		parsedGraph = reducedGraph
 		if !*bench { // be silent when benchmarking
-			fmt.Println("\n\n", *graphPath)
-			fmt.Println("Graph after Type Collapse:")
+			fmt.Fprintln(info, "\n\n", *graphPath)
+			fmt.Fprintln(info, "Graph after Type Collapse:")
 			for _, e := range reducedGraph.Edges.Slice() {
-				fmt.Printf("%v %v\n", e, Edge{Vertices: e.Vertices})
+				fmt.Fprintf(info, "%v %v\n", e, Edge{Vertices: e.Vertices})
 			}
-			fmt.Print("Removed ", count, " vertex/vertices\n\n")
+			fmt.Fprint(info, "Removed ", count, " vertex/vertices\n\n")
 		}
 	}
 
@@ -338,11 +693,12 @@ func main() {
 		}
 
 		parsedGraph = reducedGraph
+		doc.Preprocessing.GYÖOps = len(ops)
 		if !*bench { // be silent when benchmarking
-			fmt.Println("Graph after GYÖ:")
-			fmt.Println(reducedGraph)
-			fmt.Println("Reductions:")
-			fmt.Print(ops, "\n\n")
+			fmt.Fprintln(info, "Graph after GYÖ:")
+			fmt.Fprintln(info, reducedGraph)
+			fmt.Fprintln(info, "Reductions:")
+			fmt.Fprint(info, ops, "\n\n")
 		}
 
 	}
@@ -357,7 +713,7 @@ func main() {
 	if *globalBal && !*computeSubedges {
 		parsedGraph = parsedGraph.ComputeSubEdges(*width)
 
-		fmt.Println("Graph with subedges \n", parsedGraph)
+		fmt.Fprintln(info, "Graph with subedges \n", parsedGraph)
 	}
 
 	var hinget lib.Hingetree
@@ -367,14 +723,54 @@ func main() {
 		startHinge := time.Now()
 
 		hinget = lib.GetHingeTree(parsedGraph)
+		doc.Preprocessing.HingeTree = hinget.Len()
 
 		dHinge := time.Now().Sub(startHinge)
 		msecHinge = dHinge.Seconds() * float64(time.Second/time.Millisecond)
 		times = append(times, labelTime{time: msecHinge, label: "Hingetree"})
 
 		if !*bench {
-			fmt.Println("Produced Hingetree: ")
-			fmt.Println(hinget)
+			fmt.Fprintln(info, "Produced Hingetree: ")
+			fmt.Fprintln(info, hinget)
+		}
+	}
+
+	var blocks lib.Blocks
+
+	if (*hingeFlag && *blocksFlag) || (*hingeFlag && *safeFlag) || (*blocksFlag && *safeFlag) {
+		fmt.Fprintln(info, "Cannot use more than one of the hingetree optimization, block splitting and safe separators.")
+		return
+	}
+
+	if *blocksFlag {
+		startBlocks := time.Now()
+
+		blocks = lib.SplitBlocks(parsedGraph)
+		doc.Preprocessing.Blocks = blocks.Len()
+
+		dBlocks := time.Now().Sub(startBlocks)
+		msecBlocks := dBlocks.Seconds() * float64(time.Second/time.Millisecond)
+		times = append(times, labelTime{time: msecBlocks, label: "Blocks"})
+
+		if !*bench {
+			fmt.Fprintln(info, "Number of blocks: ", blocks.Len())
+		}
+	}
+
+	var safeSeps lib.SafeSeps
+
+	if *safeFlag { // the safe separators do not depend on the width
+		startSafe := time.Now()
+
+		safeSeps = lib.SplitSafeSeps(parsedGraph)
+		doc.Preprocessing.SafeSepParts = safeSeps.Len()
+
+		dSafe := time.Now().Sub(startSafe)
+		msecSafe := dSafe.Seconds() * float64(time.Second/time.Millisecond)
+		times = append(times, labelTime{time: msecSafe, label: "Safe separators"})
+
+		if !*bench {
+			fmt.Fprintln(info, "Number of parts split at safe separators: ", safeSeps.Len())
 		}
 	}
 
@@ -405,6 +801,40 @@ func main() {
 		chosen++
 	}
 
+	if *hingePortfolioFlag > 0 {
+		if *hingeFlag {
+			fmt.Fprintln(info, "The hinge portfolio computes its own hingetree, cannot be combined with the hingetree optimization.")
+			return
+		}
+		portfolio := &algo.HingePortfolio{
+			K:         *width,
+			Graph:     parsedGraph,
+			BalFactor: BalFactor,
+			Threshold: *hingePortfolioFlag,
+			Depth:     *portfolioDepth - 1,
+		}
+		solver = portfolio
+		chosen++
+	}
+
+	if *portfolioFlag != "" {
+		if *hingeFlag || *blocksFlag || *safeFlag {
+			fmt.Fprintln(info, "The portfolio cannot be combined with the hingetree optimization, block splitting or safe separators.")
+			return
+		}
+		if seeded {
+			fmt.Fprintln(info, "The portfolio races its members, and cannot be made deterministic with a seed.")
+			return
+		}
+		members, err := portfolioMembers(*portfolioFlag, parsedGraph, *width, BalFactor)
+		if err != nil {
+			fmt.Fprintln(info, "Cannot set up portfolio: ", err)
+			return
+		}
+		solver = &algo.Portfolio{K: *width, Graph: parsedGraph, Members: members}
+		chosen++
+	}
+
 	if *detKFlag {
 		det := &algo.DetKDecomp{
 			K:         *width,
@@ -437,65 +867,63 @@ func main() {
 	}
 
 	if chosen > 1 {
-		fmt.Println("Only one algorithm may be chosen at a time. Make up your mind.")
+		fmt.Fprintln(info, "Only one algorithm may be chosen at a time. Make up your mind.")
 		return
 	}
 
-	if *jCostPath != "" {
-		if !*localBal && *balDetFlag == 0 {
-			fmt.Println("Join cost can be used only in combination with: local, balDet.")
+	if *jCostPath != "" || *jStatsPath != "" {
+		if !*localBal && !*globalBal && *balDetFlag == 0 {
+			fmt.Fprintln(info, "Join cost can be used only in combination with: local, global, balDet.")
 			return
 		}
 		if *pace {
-			fmt.Println("Join cost cannot be used with PACE input format.")
+			fmt.Fprintln(info, "Join cost cannot be used with PACE input format.")
 			return
 		}
-
-		// load cost function
-		// 1. read the csv file
-		csvfile, err := os.Open(*jCostPath)
-		if err != nil {
-			fmt.Println("Can't open jCost", *jCostPath, err)
+		if *jCostPath != "" && *jStatsPath != "" {
+			fmt.Fprintln(info, "Cannot use both a join cost function and relation statistics.")
 			return
 		}
 
-		// 2. init map
-		var w lib.EdgesCostMap
-		w.Init()
-
-		r := csv.NewReader(csvfile)
-		r.FieldsPerRecord = -1
-		for {
-			record, err := r.Read()
-			if err == io.EOF {
-				break
+		// load cost model
+		var w lib.CostModel
+		if *jCostPath != "" {
+			costMap, err := loadJoinCosts(*jCostPath, parseGraph.Encoding)
+			if err != nil {
+				fmt.Fprintln(info, "Can't load jCost", *jCostPath, err)
+				return
 			}
+			w = costMap
+		} else {
+			dat, err := ioutil.ReadFile(*jStatsPath)
 			if err != nil {
-				fmt.Println(err)
+				fmt.Fprintln(info, "Can't open join statistics", *jStatsPath, err)
 				return
 			}
-
-			// 3. put the record into the map
-			last := len(record) - 1
-			cost, _ := strconv.ParseFloat(record[last], 64)
-			rec := record[:last]
-			comb := make([]int, len(rec))
-			for p, s := range rec {
-				comb[p] = parseGraph.Encoding[s]
+			statsModel, err := lib.GetStatsCostModel(dat, parsedGraph, parseGraph.Encoding)
+			if err != nil {
+				fmt.Fprintln(info, "Can't load join statistics", *jStatsPath, err)
+				return
 			}
-			sort.Ints(comb)
-			w.Put(comb, cost)
+			w = statsModel
 		}
-
-		//fmt.Println("Printing w:")
-		//wComb, wCost := w.Records()
-		//for i := 0; i < len(wComb); i++ {
-		//	fmt.Println(i, wComb[i], wCost[i])
-		//}
-		fmt.Println()
+		fmt.Fprintln(info)
 
 		// initialize solver
-		if *localBal {
+		if *optCost {
+			if !*localBal {
+				fmt.Fprintln(info, "Optimal join cost can be used only in combination with: local.")
+				return
+			}
+			opt := &algo.JCostOptBalSepLocal{
+				K:         *width,
+				Graph:     parsedGraph,
+				BalFactor: BalFactor,
+				JCosts:    w,
+				TopN:      *topN,
+			}
+			solver = opt
+		} else if *localBal {
 			local := &algo.JCostBalSepLocal{
 				K:         *width,
 				Graph:     parsedGraph,
@@ -503,36 +931,172 @@ func main() {
 				JCosts:    w,
 			}
 			solver = local
-			//} else if *globalBal {
-			//jGlobal := JCostBalSepGlobal{Graph: parsedGraph, BalFactor: BalancedFactor, JCosts: w}
-			//solver = jGlobal
+		} else if *globalBal {
+			jGlobal := &algo.JCostBalSepGlobal{
+				K:         *width,
+				Graph:     parsedGraph,
+				BalFactor: BalFactor,
+				JCosts:    w,
+			}
+			solver = jGlobal
 		} else if *balDetFlag != 0 {
-			//jBalDet := &algo.JCostBalDetKDecomp{Graph: parsedGraph, BalFactor: BalFactor, Depth: *balDetFlag - 1, JCosts: w}
-			//solver = jBalDet
+			jBalDet := &algo.JCostBalSepHybrid{
+				K:         *width,
+				Graph:     parsedGraph,
+				BalFactor: BalFactor,
+				Depth:     *balDetFlag - 1,
+				JCosts:    w,
+			}
+			solver = jBalDet
 		} else {
-			fmt.Println("Weird solver chosen.")
+			fmt.Fprintln(info, "Weird solver chosen.")
 			return
 		}
 	}
 
+	// restore undoes the preprocessing on a decomposition of the preprocessed graph
+	restore := func(decomp Decomp) Decomp {
+		if !reflect.DeepEqual(decomp, Decomp{}) || (len(ops) > 0 && parsedGraph.Edges.Len() == 0) {
+			var result bool
+			decomp.Root, result = decomp.Root.RestoreGYÖ(ops)
+			if !result {
+				fmt.Fprintln(info, "Partial decomp:", decomp.Root)
+				log.Panicln("GYÖ reduction failed")
+			}
+			decomp.Root, result = decomp.Root.RestoreTypes(removalMap)
+			if !result {
+				fmt.Fprintln(info, "Partial decomp:", decomp.Root)
+				log.Panicln("Type Collapse reduction failed")
+			}
+		}
+
+		if !reflect.DeepEqual(decomp, Decomp{}) {
+			decomp.Graph = originalGraph
+		}
+		return decomp
+	}
+
+	if *enumFlag > 0 {
+		if *width <= 0 {
+			fmt.Fprintln(info, "Enumeration requires a width.")
+			return
+		}
+		enum := &algo.Enumerator{K: *width, Graph: parsedGraph, BalFactor: BalFactor}
+		if seeded {
+			enum.Generator = lib.DeterministicSearchGen{}
+		}
+		count := 0
+		for ; count < *enumFlag && enum.HasNext(); count++ {
+			decomp := restore(enum.GetNext())
+			decomp.RestoreSubedges()
+			fmt.Fprintf(info, "Decomposition %d (Correct: %v)\n%v\n", count+1, decomp.Correct(originalGraph), decomp)
+		}
+		enum.Stop()
+		fmt.Fprintln(info, "Found", count, "distinct decompositions of width", *width)
+		return
+	}
+
 	if solver != nil {
 
-		solver.SetGenerator(lib.ParallelSearchGen{})
+		if seeded {
+			solver.SetGenerator(lib.DeterministicSearchGen{})
+		} else {
+			solver.SetGenerator(lib.ParallelSearchGen{})
+		}
+
+		var hingeStats []lib.HingeStat // stats of the hinges, from the last use of the hingetree
+
+		// the counters are updated by the solver, and read by the progress reports while it is running. As updating
+		// them is not free, they are only attached if the progress reports, the metrics or the JSON output need them.
+		var counters algo.Counters
+		counters.Init()
+		if debug, ok := solver.(algo.AlgorithmDebug); ok && (*progress > 0 || *metricsAddr != "" || *format == "json") {
+			debug.SetCounters(&counters)
+		}
+		var currentWidth, bestWidth int64
+
+		if *tracePath != "" {
+			local, ok := solver.(*algo.BalSepLocal)
+			if !ok {
+				fmt.Fprintln(info, "Tracing is only supported by the local BalSep algorithm.")
+				return
+			}
+			tracer, err := lib.CreateTracer(*tracePath)
+			if err != nil {
+				fmt.Fprintln(info, "Can't create trace", *tracePath, err)
+				return
+			}
+			defer tracer.Close()
+			local.Tracer = tracer
+		}
+
+		if *metricsAddr != "" {
+			if err := serveMetrics(*metricsAddr, &counters, &currentWidth); err != nil {
+				fmt.Fprintln(info, "Can't serve metrics on", *metricsAddr, err)
+				return
+			}
+		}
+
+		// decompose applies the chosen solver to the entire graph for width k, using the chosen optimizations
+		decompose := func(k int) Decomp {
+			atomic.StoreInt64(&currentWidth, int64(k))
+			if *hingeFlag {
+				var decomp Decomp
+				decomp, hingeStats = hinget.DecompHingeStats(solver, parsedGraph)
+				return decomp
+			}
+			if *blocksFlag {
+				return blocks.DecompBlocks(solver, parsedGraph)
+			}
+			if *safeFlag {
+				return safeSeps.DecompSafeSeps(solver, parsedGraph, k)
+			}
+			return solver.FindDecomp()
+		}
 
 		var decomp Decomp
+		var widthsTried int64 // number of widths the solver was run with
+		doc.Preprocessing.Edges = parsedGraph.Edges.Len()
+		doc.Preprocessing.Vertices = len(parsedGraph.Edges.Vertices())
 		start := time.Now()
 
-		if *exact {
+		stopProgress := make(chan struct{})
+		if *progress > 0 {
+			var w io.Writer = os.Stderr
+			if *progressFile != "" {
+				f, err := os.Create(*progressFile)
+				if err != nil {
+					fmt.Fprintln(info, "Can't create progress file", *progressFile, err)
+					return
+				}
+				defer f.Close()
+				w = f
+			}
+			go reportProgress(w, *progressFile != "", *progress, stopProgress, func() progressReport {
+				p := counters.Progress()
+				return progressReport{Time: time.Since(start).Seconds() * 1000,
+					Width: atomic.LoadInt64(&currentWidth), BestWidth: atomic.LoadInt64(&bestWidth),
+					Completion: p.Completion, Backtracks: p.Backtracks, CacheLookups: p.CacheLookups,
+					CacheHits: p.CacheHits}
+			})
+		}
+
+		// acyclic graphs have a join tree, a decomposition of width 1, so no search is needed
+		joinTree, acyclic := lib.JoinTreeShortcut(solver, parsedGraph)
+
+		if acyclic {
+			decomp = joinTree
+			if *exact || *approx > 0 {
+				*width = 1 // for correct output
+			}
+		} else if *exact {
 			solved := false
 			k := 1
 			for ; !solved; k++ {
 				solver.SetWidth(k)
+				widthsTried++
 
-				if *hingeFlag {
-					decomp = hinget.DecompHinge(solver, parsedGraph)
-				} else {
-					decomp = solver.FindDecomp()
-				}
+				decomp = decompose(k)
 
 				solved = decomp.Correct(parsedGraph)
 			}
@@ -546,21 +1110,20 @@ func main() {
 				firstApprox.SetWidth(k)
 				decomp = firstApprox.FindDecomp()
 				k = decomp.CheckWidth()
+				atomic.StoreInt64(&bestWidth, int64(k))
 				solved := false
 
 				var newDecomp Decomp
 				for !solved {
 					newK := k - 1
 					solver.SetWidth(newK)
+					atomic.AddInt64(&widthsTried, 1)
 
-					if *hingeFlag {
-						newDecomp = hinget.DecompHinge(solver, parsedGraph)
-					} else {
-						newDecomp = solver.FindDecomp()
-					}
+					newDecomp = decompose(newK)
 					if newDecomp.Correct(parsedGraph) {
 						k = newDecomp.CheckWidth()
 						decomp = newDecomp
+						atomic.StoreInt64(&bestWidth, int64(k))
 					} else {
 						solved = true
 					}
@@ -575,13 +1138,11 @@ func main() {
 				*width = decomp.CheckWidth()
 			}
 		} else {
-			if *hingeFlag {
-				decomp = hinget.DecompHinge(solver, parsedGraph)
-			} else {
-				decomp = solver.FindDecomp()
-			}
+			decomp = decompose(*width)
+			widthsTried++
 		}
 
+		close(stopProgress)
 		d := time.Now().Sub(start)
 		msec := d.Seconds() * float64(time.Second/time.Millisecond)
 		times = append(times, labelTime{time: msec, label: "Decomposition"})
@@ -591,32 +1152,76 @@ func main() {
 			decomp.Root.RemoveVertices(addedVertices)
 		}
 
-		if !reflect.DeepEqual(decomp, Decomp{}) || (len(ops) > 0 && parsedGraph.Edges.Len() == 0) {
-			var result bool
-			decomp.Root, result = decomp.Root.RestoreGYÖ(ops)
-			if !result {
-				fmt.Println("Partial decomp:", decomp.Root)
-				log.Panicln("GYÖ reduction failed")
-			}
-			decomp.Root, result = decomp.Root.RestoreTypes(removalMap)
-			if !result {
-				fmt.Println("Partial decomp:", decomp.Root)
-				log.Panicln("Type Collapse reduction failed")
-			}
+		decomp = restore(decomp)
+
+		algorithmName := solver.Name()
+		if acyclic {
+			algorithmName = "Join Tree"
 		}
 
-		if !reflect.DeepEqual(decomp, Decomp{}) {
-			decomp.Graph = originalGraph
+		if portfolio, ok := solver.(*algo.HingePortfolio); ok {
+			hingeStats = portfolio.Stats
 		}
 
 		if *shellio {
 			outputShellio(decomp)
+		} else if *format == "json" {
+			doc.Algorithm = algorithmName
+			doc.K = *width
+			doc.Counters["widthsTried"] = atomic.LoadInt64(&widthsTried)
+			p := counters.Progress()
+			for _, v := range p.Backtracks {
+				doc.Counters["backtracks"] += int64(v)
+			}
+			doc.Counters["separatorsTested"] = p.Separators
+			doc.Counters["balancedFound"] = p.Balanced
+			doc.Counters["subedgesTried"] = p.Subedges
+			doc.Counters["cacheLookups"] = p.CacheLookups
+			doc.Counters["cacheHits"] = p.CacheHits
+			doc.Counters["cacheEntries"] = p.CacheEntries
+			if portfolio, ok := solver.(*algo.Portfolio); ok && !acyclic {
+				doc.Winner = portfolio.Winner
+			}
+			if !acyclic {
+				for _, stat := range hingeStats {
+					doc.Hinges = append(doc.Hinges, jsonHinge{Edges: stat.Edges, Algorithm: stat.Algorithm,
+						Width: stat.Width, Time: stat.Time.Seconds() * 1000, Solved: stat.Solved})
+				}
+			}
+			outputJSON(out, doc, decomp, times, originalGraph, *gml, *jsonFlag)
 		} else {
-			outputStanza(solver.Name(), decomp, times, originalGraph, *gml, *jsonFlag, *width, false)
+			outputStanza(info, algorithmName, decomp, times, originalGraph, *gml, *jsonFlag, *width, false)
+			if *acyclicityFlag {
+				fmt.Fprintln(info, "Acyclicity: ", originalGraph.GetAcyclicity())
+			}
+		}
+
+		if portfolio, ok := solver.(*algo.Portfolio); ok && !acyclic && !*bench && !*shellio {
+			if portfolio.Winner != "" {
+				fmt.Fprintln(info, "\nDecided by: ", portfolio.Winner)
+			} else {
+				fmt.Fprintln(info, "\nNo algorithm could decide the width")
+			}
+		}
+
+		if (*hingeFlag || *hingePortfolioFlag > 0) && !acyclic && !*bench && !*shellio {
+			fmt.Fprintln(info, "\nHinges:")
+			for i, stat := range hingeStats {
+				fmt.Fprintf(info, "Hinge %d: %v\n", i, stat)
+			}
+		}
+
+		if opt, ok := solver.(*algo.JCostOptBalSepLocal); ok && *topN > 1 && !*shellio {
+			for i, alternative := range opt.Alternatives() {
+				alternative = restore(alternative)
+				alternative.RestoreSubedges()
+				fmt.Fprintf(info, "\nAlternative %d (Total cost: %.2f, Correct: %v)\n%v", i+1, alternative.TotalCost(),
+					alternative.Correct(originalGraph), alternative)
+			}
 		}
 
 		return
 	}
 
-	fmt.Println("No algorithm or procedure selected.")
+	fmt.Fprintln(info, "No algorithm or procedure selected.")
 }
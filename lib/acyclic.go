@@ -0,0 +1,256 @@
+package lib
+
+// acyclic.go provides tests for the different notions of hypergraph acyclicity, and the construction of join trees
+// for α-acyclic hypergraphs. Only the edges of a graph are considered, not its special edges.
+
+// IsBetaAcyclic checks if the graph is β-acyclic, i.e. if every subset of its edges is α-acyclic. This holds iff
+// repeatedly removing nest points, vertices whose incident edges form a chain under inclusion, removes all vertices.
+func (g Graph) IsBetaAcyclic() bool {
+	edges := vertexSets(g.Edges)
+
+	for {
+		removed := false
+		for v := range allVertices(edges) {
+			if isNestPoint(edges, v) {
+				for _, e := range edges {
+					delete(e, v)
+				}
+				removed = true
+			}
+		}
+
+		if !removed {
+			return len(allVertices(edges)) == 0
+		}
+	}
+}
+
+// IsGammaAcyclic checks if the graph is γ-acyclic, using the reduction by Fagin '83: vertices contained in only one
+// edge, edges of at most one vertex, duplicate edges and duplicate vertices (contained in the same edges) are removed
+// until none are left. The graph is γ-acyclic iff nothing remains.
+func (g Graph) IsGammaAcyclic() bool {
+	edges := vertexSets(g.Edges)
+
+	for {
+		changed := false
+
+		// vertices contained in only one edge
+		incidence := incidentEdges(edges)
+		for v, inc := range incidence {
+			if len(inc) == 1 {
+				delete(edges[inc[0]], v)
+				changed = true
+			}
+		}
+
+		// edges of at most one vertex, and duplicate edges
+		var kept []map[int]struct{}
+	EDGES:
+		for _, e := range edges {
+			if len(e) <= 1 {
+				changed = true
+				continue
+			}
+			for _, o := range kept {
+				if sameSet(e, o) {
+					changed = true
+					continue EDGES
+				}
+			}
+			kept = append(kept, e)
+		}
+		edges = kept
+
+		// vertices contained in the same edges
+		seen := make(map[uint64][][]int) // incidences seen so far, by their hash
+		for v, inc := range incidentEdges(edges) {
+			key := hashInts(inc)
+			if containsInts(seen[key], inc) {
+				for _, i := range inc {
+					delete(edges[i], v)
+				}
+				changed = true
+				continue
+			}
+			seen[key] = append(seen[key], inc)
+		}
+
+		if !changed {
+			return len(edges) == 0
+		}
+	}
+}
+
+// Acyclicity records which notions of acyclicity a graph satisfies. Each is implied by the next: a γ-acyclic graph
+// is β-acyclic, and a β-acyclic graph is α-acyclic.
+type Acyclicity struct {
+	Alpha bool
+	Beta  bool
+	Gamma bool
+}
+
+// GetAcyclicity determines the acyclicity of the graph, only testing the stronger notions if the weaker ones hold
+func (g Graph) GetAcyclicity() Acyclicity {
+	var output Acyclicity
+
+	output.Alpha = g.IsAlphaAcyclic()
+	output.Beta = output.Alpha && g.IsBetaAcyclic()
+	output.Gamma = output.Beta && g.IsGammaAcyclic()
+
+	return output
+}
+
+func (a Acyclicity) String() string {
+	name := func(holds bool, notion string) string {
+		if holds {
+			return notion + "-acyclic"
+		}
+		return "not " + notion + "-acyclic"
+	}
+
+	return name(a.Alpha, "α") + ", " + name(a.Beta, "β") + ", " + name(a.Gamma, "γ")
+}
+
+// GetJoinTree builds a join tree of an α-acyclic graph, i.e. a decomposition of width 1 in which each node is
+// covered by a single edge. Returns false if the graph is not α-acyclic or has special edges.
+func (g Graph) GetJoinTree() (Decomp, bool) {
+	if len(g.Special) > 0 {
+		return Decomp{}, false
+	}
+
+	reduced, ops := g.GYÖReduct()
+	if reduced.Edges.Len() > 0 {
+		return Decomp{}, false
+	}
+
+	root, ok := Node{}.RestoreGYÖ(ops)
+	if !ok {
+		return Decomp{}, false
+	}
+
+	return Decomp{Graph: g, Root: root}, true
+}
+
+// A CostAlgorithm searches for decompositions of low join cost, so a join tree found regardless of the costs can't
+// replace its search
+type CostAlgorithm interface {
+	AlgorithmH
+	GetCosts() CostModel // the join costs considered, nil if none
+}
+
+// JoinTreeShortcut returns the join tree of g, if g is α-acyclic and the algorithm does not consider join costs. No
+// search is needed in this case, as the join tree is a decomposition of width 1.
+func JoinTreeShortcut(alg AlgorithmH, g Graph) (Decomp, bool) {
+	if g.Edges.Len() == 0 {
+		return Decomp{}, false
+	}
+	if c, ok := alg.(CostAlgorithm); ok && c.GetCosts() != nil {
+		return Decomp{}, false
+	}
+
+	return g.GetJoinTree()
+}
+
+// DecompGraph decomposes g with the algorithm, or returns its join tree directly if JoinTreeShortcut applies. It is
+// the common entry point for decomposing a graph, or any part of it.
+func DecompGraph(alg AlgorithmH, g Graph) Decomp {
+	if joinTree, ok := JoinTreeShortcut(alg, g); ok {
+		return joinTree
+	}
+
+	return alg.FindDecompGraph(g)
+}
+
+// containsInts checks if a slice equal to values is among the lists
+func containsInts(lists [][]int, values []int) bool {
+OUTER:
+	for _, l := range lists {
+		if len(l) != len(values) {
+			continue
+		}
+		for i := range l {
+			if l[i] != values[i] {
+				continue OUTER
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// vertexSets turns the edges into sets of vertices
+func vertexSets(edges Edges) []map[int]struct{} {
+	var output []map[int]struct{}
+
+	for _, e := range edges.Slice() {
+		set := make(map[int]struct{}, len(e.Vertices))
+		for _, v := range e.Vertices {
+			set[v] = Empty
+		}
+		output = append(output, set)
+	}
+
+	return output
+}
+
+// allVertices collects the vertices of a slice of sets
+func allVertices(edges []map[int]struct{}) map[int]struct{} {
+	output := make(map[int]struct{})
+
+	for _, e := range edges {
+		for v := range e {
+			output[v] = Empty
+		}
+	}
+
+	return output
+}
+
+// incidentEdges maps each vertex to the positions of the sets containing it, in ascending order
+func incidentEdges(edges []map[int]struct{}) map[int][]int {
+	output := make(map[int][]int)
+
+	for i, e := range edges {
+		for v := range e {
+			output[v] = append(output[v], i)
+		}
+	}
+
+	return output
+}
+
+// isNestPoint checks if the sets containing v form a chain under inclusion
+func isNestPoint(edges []map[int]struct{}, v int) bool {
+	var incident []map[int]struct{}
+	for _, e := range edges {
+		if _, ok := e[v]; ok {
+			incident = append(incident, e)
+		}
+	}
+
+	for i := range incident {
+		for j := i + 1; j < len(incident); j++ {
+			if !subsetOf(incident[i], incident[j]) && !subsetOf(incident[j], incident[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func subsetOf(a, b map[int]struct{}) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for v := range a {
+		if _, ok := b[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(a, b map[int]struct{}) bool {
+	return len(a) == len(b) && subsetOf(a, b)
+}
@@ -41,10 +41,24 @@ func mem(as []int, b int) bool {
 	return false
 }
 
+// smallSets is the bound on the product of the sizes of two slices, up to which set operations on them simply
+// compare all pairs instead of building a VertexSet
+const smallSets = 64
+
 // Diff computes the set difference between two slices a b
 func Diff(a, b []int) []int {
 	output := make([]int, 0, len(a))
 
+	if len(a)*len(b) > smallSets {
+		set := NewVertexSet(b)
+		for _, n := range a {
+			if !set.Has(n) {
+				output = append(output, n)
+			}
+		}
+		return output
+	}
+
 OUTER:
 	for _, n := range a {
 		for _, k := range b {
@@ -88,6 +102,17 @@ func diffEdges(a Edges, e ...Edge) Edges {
 // Inter is the set intersection between slices as and bs
 func Inter(as, bs []int) []int {
 	var output []int
+
+	if len(as)*len(bs) > smallSets {
+		set := NewVertexSet(bs)
+		for _, a := range as {
+			if set.Has(a) {
+				output = append(output, a)
+			}
+		}
+		return output
+	}
+
 OUTER:
 	for _, a := range as {
 		for _, b := range bs {
@@ -106,14 +131,13 @@ func Subset(as []int, bs []int) bool {
 	if len(as) == 0 {
 		return true
 	}
-	encounteredB := make(map[int]struct{})
-	var Empty struct{}
-	for _, b := range bs {
-		encounteredB[b] = Empty
+
+	if len(as)*len(bs) > smallSets {
+		return NewVertexSet(bs).HasAll(as)
 	}
 
 	for _, a := range as {
-		if _, ok := encounteredB[a]; !ok {
+		if !mem(bs, a) {
 			return false
 		}
 	}
@@ -0,0 +1,230 @@
+package lib
+
+// blocks.go implements the splitting of a hypergraph at its articulation vertices, such that each resulting block
+// can be decomposed independently
+
+import (
+	"reflect"
+
+	"github.com/cem-okulmus/disjoint"
+)
+
+// Blocks stores the blocks of a graph, which overlap in at most one (articulation) vertex, and how they are
+// connected to each other. The blocks form a tree, rooted at the first block.
+type Blocks struct {
+	Blocks []Graph
+	parent []int // the block each block is attached to, or -1
+	cut    []int // the vertex shared by each block and its parent, or -1 if they share none
+}
+
+// Len returns the number of blocks
+func (b Blocks) Len() int {
+	return len(b.Blocks)
+}
+
+// SplitBlocks splits a graph into its blocks, using the biconnected components of its incidence graph. Any two
+// biconnected components that share an edge are merged into the same block, so that each edge belongs to exactly one
+// block, and two blocks share at most one articulation vertex.
+func SplitBlocks(g Graph) Blocks {
+	edges := g.Edges.Slice()
+	vertices := g.Edges.Vertices()
+
+	// nodes of the incidence graph: vertices first, followed by the edges
+	index := make(map[int]int)
+	for i, v := range vertices {
+		index[v] = i
+	}
+	nodes := len(vertices) + len(edges)
+
+	adj := make([][]int, nodes)
+	for i, e := range edges {
+		for _, v := range RemoveDuplicates(append([]int{}, e.Vertices...)) {
+			adj[index[v]] = append(adj[index[v]], len(vertices)+i)
+			adj[len(vertices)+i] = append(adj[len(vertices)+i], index[v])
+		}
+	}
+
+	// edges in the same biconnected component are merged
+	edgeSets := make([]*disjoint.Element, len(edges))
+	for i := range edgeSets {
+		edgeSets[i] = disjoint.NewElement()
+	}
+
+	disc := make([]int, nodes)
+	low := make([]int, nodes)
+	counter := 0
+	var stack [][2]int
+
+	var dfs func(u, parent int)
+	dfs = func(u, parent int) {
+		counter++
+		disc[u] = counter
+		low[u] = counter
+
+		for _, w := range adj[u] {
+			if w == parent {
+				continue
+			}
+			if disc[w] == 0 {
+				stack = append(stack, [2]int{u, w})
+				dfs(w, u)
+				if low[w] < low[u] {
+					low[u] = low[w]
+				}
+				if low[w] >= disc[u] { // u separates the component containing w
+					first := -1
+					for {
+						top := stack[len(stack)-1]
+						stack = stack[:len(stack)-1]
+						for _, n := range top {
+							if n >= len(vertices) {
+								if first == -1 {
+									first = n - len(vertices)
+								} else {
+									disjoint.Union(edgeSets[first], edgeSets[n-len(vertices)])
+								}
+							}
+						}
+						if top[0] == u && top[1] == w {
+							break
+						}
+					}
+				}
+			} else if disc[w] < disc[u] {
+				stack = append(stack, [2]int{u, w})
+				if disc[w] < low[u] {
+					low[u] = disc[w]
+				}
+			}
+		}
+	}
+
+	for u := 0; u < nodes; u++ {
+		if disc[u] == 0 {
+			dfs(u, -1)
+		}
+	}
+
+	// collect the blocks
+	var output Blocks
+	blockOf := make(map[*disjoint.Element]int)
+	var blockEdges [][]Edge
+	for i := range edges {
+		root := edgeSets[i].Find()
+		if _, ok := blockOf[root]; !ok {
+			blockOf[root] = len(blockEdges)
+			blockEdges = append(blockEdges, []Edge{})
+		}
+		blockEdges[blockOf[root]] = append(blockEdges[blockOf[root]], edges[i])
+	}
+
+	blocksOfVertex := make(map[int][]int)
+	for i := range blockEdges {
+		block := Graph{Edges: NewEdges(blockEdges[i])}
+		output.Blocks = append(output.Blocks, block)
+		for _, v := range block.Vertices() {
+			blocksOfVertex[v] = append(blocksOfVertex[v], i)
+		}
+	}
+
+	// arrange the blocks in a tree, via a BFS over shared vertices
+	output.parent = make([]int, len(output.Blocks))
+	output.cut = make([]int, len(output.Blocks))
+	visited := make([]bool, len(output.Blocks))
+
+	for start := range output.Blocks {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		output.parent[start] = -1
+		output.cut[start] = -1
+		if start > 0 { // blocks of different connected components are attached anywhere
+			output.parent[start] = 0
+		}
+
+		queue := []int{start}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, v := range output.Blocks[current].Vertices() {
+				for _, other := range blocksOfVertex[v] {
+					if visited[other] {
+						continue
+					}
+					visited[other] = true
+					output.parent[other] = current
+					output.cut[other] = v
+					queue = append(queue, other)
+				}
+			}
+		}
+	}
+
+	return output
+}
+
+// Restore glues the decomps of all blocks into one decomp, by connecting the nodes covering the shared vertices.
+// The decomps must be given in the same order as the blocks.
+func (b Blocks) Restore(decomps []Decomp) (Node, bool) {
+	if len(decomps) != len(b.Blocks) {
+		return Node{}, false
+	}
+	for i := range decomps {
+		if reflect.DeepEqual(decomps[i], Decomp{}) {
+			return Node{}, false
+		}
+	}
+	if len(decomps) == 0 {
+		return Node{}, true
+	}
+
+	// glue in BFS order, so that each block is attached after its parent
+	children := make([][]int, len(b.Blocks))
+	var roots []int
+	for i := range b.Blocks {
+		if b.parent[i] == -1 {
+			roots = append(roots, i)
+		} else {
+			children[b.parent[i]] = append(children[b.parent[i]], i)
+		}
+	}
+
+	output := decomps[roots[0]].Root
+	queue := append([]int{}, roots...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, c := range children[current] {
+			child := decomps[c].Root
+			if b.cut[c] != -1 {
+				connecting := []int{b.cut[c]}
+				child = child.RerootEdge(connecting)
+				output = output.RerootEdge(connecting)
+			}
+			output.Children = append(output.Children, child)
+			queue = append(queue, c)
+		}
+	}
+
+	return output, true
+}
+
+// DecompBlocks computes a decomposition of the original input graph, decomposing all blocks in parallel, each with its
+// own copy of the algorithm, or one after the other if the algorithm can't be copied. As soon as any block is rejected,
+// the others are cancelled.
+func (b Blocks) DecompBlocks(alg AlgorithmH, g Graph) Decomp {
+	decomps, _, ok := decompAllWith(alg, b.Blocks)
+	if !ok {
+		return Decomp{}
+	}
+
+	root, ok := b.Restore(decomps)
+	if !ok {
+		return Decomp{}
+	}
+
+	return Decomp{Graph: g, Root: root}
+}
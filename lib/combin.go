@@ -47,6 +47,28 @@ func extendedBinom(n int, k int) int {
 	return output
 }
 
+// CombinationCount returns the number of combinations the generators of SplitCombin produce for the same arguments.
+// It is computed in floating point, as the count easily overflows for large searches.
+func CombinationCount(n int, k int, unextended bool) float64 {
+	if k > n {
+		k = n
+	}
+
+	var output float64
+	for i := k; i >= 1; i-- {
+		b := 1.0
+		for j := 1; j <= i; j++ {
+			b = b * float64(n-i+j) / float64(j)
+		}
+		output = output + b
+		if unextended {
+			break
+		}
+	}
+
+	return output
+}
+
 // A CombinationIterator generates combinations iteratively.
 type CombinationIterator struct {
 	N           int
@@ -0,0 +1,250 @@
+package lib
+
+// components.go implements the computation of the connected components of a graph relative to a separator, using
+// a workspace that is reused between calls
+
+// A CompWorkspace holds the memory needed to compute the components of a graph. The vertices of the graph are
+// re-indexed densely, so that union-find and the marking of separator vertices work on plain slices. The index is
+// kept as long as the workspace is used for the same graph, making repeated calls for different separators free of
+// allocations. The zero value is ready to use.
+//
+// A workspace must not be used by several goroutines at once, and the edges of a graph must not be modified while a
+// workspace is used for it.
+type CompWorkspace struct {
+	edges   []Edge        // the edges the index was built for
+	special [][]Edge      // the special edges the index was built for
+	index   map[int]int32 // the dense index of each vertex
+	edgeIdx [][]int32     // the vertices of each edge, densely indexed
+	specIdx [][]int32     // the vertices of each special edge, densely indexed
+	parent  []int32       // union-find forest
+	size    []int32       // size of each tree in the forest, for union by size
+	sep     []uint64      // bitset of separator vertices
+	count   []int         // number of (special) edges in the component of each root
+	compOf  []int32       // position of the component of each root in the output of GetComponents
+}
+
+// prepare sets up the workspace for the graph, reusing the index if it was built for the same graph
+func (ws *CompWorkspace) prepare(g *Graph) {
+	edges := g.Edges.Slice()
+	if ws.index != nil && sameSlice(ws.edges, edges) && len(ws.special) == len(g.Special) {
+		same := true
+		for i := range g.Special {
+			same = same && sameSlice(ws.special[i], g.Special[i].Slice())
+		}
+		if same {
+			return
+		}
+	}
+	ws.edges = edges
+	ws.special = ws.special[:0]
+	for i := range g.Special {
+		ws.special = append(ws.special, g.Special[i].Slice())
+	}
+
+	ws.index = make(map[int]int32)
+	indexOf := func(v int) int32 {
+		i, ok := ws.index[v]
+		if !ok {
+			i = int32(len(ws.index))
+			ws.index[v] = i
+		}
+		return i
+	}
+
+	ws.edgeIdx = make([][]int32, len(edges))
+	for i := range edges {
+		ws.edgeIdx[i] = make([]int32, len(edges[i].Vertices))
+		for j, v := range edges[i].Vertices {
+			ws.edgeIdx[i][j] = indexOf(v)
+		}
+	}
+	ws.specIdx = make([][]int32, len(g.Special))
+	for i := range g.Special {
+		vertices := g.Special[i].Vertices()
+		ws.specIdx[i] = make([]int32, len(vertices))
+		for j, v := range vertices {
+			ws.specIdx[i][j] = indexOf(v)
+		}
+	}
+
+	n := len(ws.index)
+	ws.parent = make([]int32, n)
+	ws.size = make([]int32, n)
+	ws.sep = make([]uint64, (n+63)/64)
+	ws.count = make([]int, n)
+	ws.compOf = make([]int32, n)
+}
+
+func sameSlice(a, b []Edge) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+func (ws *CompWorkspace) inSep(v int32) bool {
+	return ws.sep[v/64]&(1<<uint(v%64)) != 0
+}
+
+func (ws *CompWorkspace) find(v int32) int32 {
+	for ws.parent[v] != v {
+		ws.parent[v] = ws.parent[ws.parent[v]] // path halving
+		v = ws.parent[v]
+	}
+	return v
+}
+
+func (ws *CompWorkspace) union(a, b int32) {
+	a, b = ws.find(a), ws.find(b)
+	if a == b {
+		return
+	}
+	if ws.size[a] < ws.size[b] {
+		a, b = b, a
+	}
+	ws.parent[b] = a
+	ws.size[a] = ws.size[a] + ws.size[b]
+}
+
+// link unites all vertices of an edge not in the separator
+func (ws *CompWorkspace) link(vertices []int32) {
+	first := int32(-1)
+	for _, v := range vertices {
+		if ws.inSep(v) {
+			continue
+		}
+		if first == -1 {
+			first = v
+		} else {
+			ws.union(first, v)
+		}
+	}
+}
+
+// rep returns the root of the component of an edge, or -1 if all its vertices are in the separator
+func (ws *CompWorkspace) rep(vertices []int32) int32 {
+	for _, v := range vertices {
+		if !ws.inSep(v) {
+			return ws.find(v)
+		}
+	}
+	return -1
+}
+
+// split computes the components of the graph relative to the separator. It must be followed by a call of clear.
+func (ws *CompWorkspace) split(g *Graph, sep Edges) {
+	ws.prepare(g)
+
+	for _, e := range sep.Slice() {
+		for _, v := range e.Vertices {
+			if i, ok := ws.index[v]; ok {
+				ws.sep[i/64] = ws.sep[i/64] | 1<<uint(i%64)
+			}
+		}
+	}
+
+	for i := range ws.parent {
+		ws.parent[i] = int32(i)
+		ws.size[i] = 1
+		ws.count[i] = 0
+		ws.compOf[i] = -1
+	}
+
+	for _, vertices := range ws.edgeIdx {
+		ws.link(vertices)
+	}
+	for _, vertices := range ws.specIdx {
+		ws.link(vertices)
+	}
+}
+
+// clear removes the separator from the workspace
+func (ws *CompWorkspace) clear() {
+	for i := range ws.sep {
+		ws.sep[i] = 0
+	}
+}
+
+// balanced checks if no component of the graph relative to the separator has more than limit (special) edges
+func (ws *CompWorkspace) balanced(g *Graph, sep Edges, limit int) bool {
+	ws.split(g, sep)
+	defer ws.clear()
+
+	for _, vertices := range ws.edgeIdx {
+		if r := ws.rep(vertices); r != -1 {
+			ws.count[r]++
+			if ws.count[r] > limit {
+				return false
+			}
+		}
+	}
+	for _, vertices := range ws.specIdx {
+		r := ws.rep(vertices)
+		if r == -1 {
+			if limit < 1 { // special edges covered by the separator form components of their own
+				return false
+			}
+			continue
+		}
+		ws.count[r]++
+		if ws.count[r] > limit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetComponents computes the connected components of the graph relative to a separator. The components containing
+// edges come first, in the order of their first edges, followed by those only containing special edges. Edges
+// covered by the separator are not part of any component, and are returned separately, together with a map from
+// the names of the other edges to the position of their component.
+func (g Graph) GetComponents(sep Edges, ws *CompWorkspace) ([]Graph, map[int]int, []Edge) {
+	ws.split(&g, sep)
+	defer ws.clear()
+
+	var comps [][]Edge
+	var compsSp [][]Edges
+	var isolatedEdges []Edge
+	var isolatedSp []Edges
+	edgeToComp := make(map[int]int)
+
+	for i, e := range g.Edges.Slice() {
+		r := ws.rep(ws.edgeIdx[i])
+		if r == -1 {
+			isolatedEdges = append(isolatedEdges, e)
+			continue
+		}
+		if ws.compOf[r] == -1 {
+			ws.compOf[r] = int32(len(comps))
+			comps = append(comps, nil)
+			compsSp = append(compsSp, nil)
+		}
+		comps[ws.compOf[r]] = append(comps[ws.compOf[r]], e)
+		edgeToComp[e.Name] = int(ws.compOf[r])
+	}
+
+	for i := range g.Special {
+		r := ws.rep(ws.specIdx[i])
+		if r == -1 {
+			isolatedSp = append(isolatedSp, g.Special[i])
+			continue
+		}
+		if ws.compOf[r] == -1 {
+			ws.compOf[r] = int32(len(comps))
+			comps = append(comps, []Edge{})
+			compsSp = append(compsSp, nil)
+		}
+		compsSp[ws.compOf[r]] = append(compsSp[ws.compOf[r]], g.Special[i])
+	}
+
+	var outputG []Graph
+	for i := range comps {
+		outputG = append(outputG, Graph{Edges: NewEdges(comps[i]), Special: compsSp[i]})
+	}
+	for i := range isolatedSp {
+		outputG = append(outputG, Graph{Edges: NewEdges([]Edge{}), Special: []Edges{isolatedSp[i]}})
+	}
+
+	return outputG, edgeToComp, isolatedEdges
+}
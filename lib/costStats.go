@@ -0,0 +1,193 @@
+package lib
+
+// costStats.go implements a cost model that estimates join costs from per-relation statistics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultRows is the cardinality assumed for relations without any statistics
+const DefaultRows = 1000.0
+
+// RelationStats contains the statistics of a single relation: its number of rows and the number of distinct values
+// for each of its attributes
+type RelationStats struct {
+	Rows     float64            `json:"rows"`
+	Distinct map[string]float64 `json:"distinct"`
+}
+
+// estimate is an intermediate (or base) relation, used during the cost estimation
+type estimate struct {
+	rows     float64
+	distinct map[int]float64
+}
+
+// StatsCostModel estimates the costs of edge combinations on demand, using the textbook estimates for the
+// size of natural joins: |R ⋈ S| = |R| * |S| / Π max(V(R,a), V(S,a)), ranging over all shared attributes a.
+// The cost of an edge combination is the sum of all intermediate results, when joining the relations in a greedy
+// order (always picking the join with the smallest result next).
+type StatsCostModel struct {
+	base  map[int]estimate
+	cache map[uint64]float64
+	mux   sync.RWMutex
+}
+
+// GetStatsCostModel sets up a cost model for a graph from statistics in JSON format. The statistics map the names
+// of relations (edges) to their row count and distinct values per attribute (vertex), such as
+//
+//	{"R": {"rows": 1000, "distinct": {"x": 100, "y": 10}}}
+//
+// Edges without statistics are assumed to have DefaultRows rows, and attributes without statistics are assumed to
+// be keys of their relation.
+func GetStatsCostModel(data []byte, graph Graph, encoding map[string]int) (*StatsCostModel, error) {
+	var stats map[string]RelationStats
+
+	err := json.Unmarshal(data, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range stats {
+		if _, ok := encoding[name]; !ok {
+			return nil, fmt.Errorf("statistics for unknown relation %v", name)
+		}
+	}
+
+	return NewStatsCostModel(graph, encoding, stats), nil
+}
+
+// NewStatsCostModel sets up a cost model for a graph, from already parsed statistics
+func NewStatsCostModel(graph Graph, encoding map[string]int, stats map[string]RelationStats) *StatsCostModel {
+	model := StatsCostModel{base: make(map[int]estimate), cache: make(map[uint64]float64)}
+
+	decoding := make(map[int]string)
+	for k, v := range encoding {
+		decoding[v] = k
+	}
+
+	for _, e := range graph.Edges.Slice() {
+		relStats, ok := stats[decoding[e.Name]]
+		rows := relStats.Rows
+		if !ok || rows <= 0 {
+			rows = DefaultRows
+		}
+
+		est := estimate{rows: rows, distinct: make(map[int]float64)}
+		for _, v := range e.Vertices {
+			d, ok := relStats.Distinct[decoding[v]]
+			if !ok || d <= 0 || d > rows {
+				d = rows
+			}
+			est.distinct[v] = d
+		}
+		model.base[e.Name] = est
+	}
+
+	return &model
+}
+
+// Cost of an edge combination, computed on first use
+func (m *StatsCostModel) Cost(edgeComb []int) float64 {
+	comb := make([]int, len(edgeComb))
+	copy(comb, edgeComb)
+	sort.Ints(comb)
+	code := hash(comb)
+
+	m.mux.RLock()
+	c, ok := m.cache[code]
+	m.mux.RUnlock()
+	if ok {
+		return c
+	}
+
+	c = m.computeCost(comb)
+
+	m.mux.Lock()
+	m.cache[code] = c
+	m.mux.Unlock()
+
+	return c
+}
+
+func (m *StatsCostModel) computeCost(comb []int) float64 {
+	var remaining []estimate
+	for _, e := range comb {
+		if est, ok := m.base[e]; ok {
+			remaining = append(remaining, est)
+		} else {
+			remaining = append(remaining, estimate{rows: DefaultRows})
+		}
+	}
+
+	if len(remaining) == 0 {
+		return 0
+	}
+	if len(remaining) == 1 {
+		return remaining[0].rows
+	}
+
+	// start with the smallest relation
+	current := 0
+	for i := range remaining {
+		if remaining[i].rows < remaining[current].rows {
+			current = i
+		}
+	}
+	result := remaining[current]
+	remaining = append(remaining[:current], remaining[current+1:]...)
+
+	var cost float64
+	for len(remaining) > 0 {
+		best := 0
+		bestJoin := joinEstimate(result, remaining[0])
+		for i := 1; i < len(remaining); i++ {
+			join := joinEstimate(result, remaining[i])
+			if join.rows < bestJoin.rows {
+				best = i
+				bestJoin = join
+			}
+		}
+
+		result = bestJoin
+		cost = cost + result.rows
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return cost
+}
+
+// joinEstimate computes the estimated size and distinct values of the natural join of two relations
+func joinEstimate(r, s estimate) estimate {
+	rows := r.rows * s.rows
+	distinct := make(map[int]float64)
+	var divisors []float64
+
+	for v, dr := range r.distinct {
+		if ds, ok := s.distinct[v]; ok {
+			divisors = append(divisors, math.Max(dr, ds))
+			distinct[v] = math.Min(dr, ds)
+		} else {
+			distinct[v] = dr
+		}
+	}
+	for v, ds := range s.distinct {
+		if _, ok := r.distinct[v]; !ok {
+			distinct[v] = ds
+		}
+	}
+
+	sort.Float64s(divisors) // keeps the estimate deterministic
+	for _, d := range divisors {
+		rows = rows / d
+	}
+
+	rows = math.Max(rows, 1)
+	for v := range distinct {
+		distinct[v] = math.Min(distinct[v], rows)
+	}
+
+	return estimate{rows: rows, distinct: distinct}
+}
@@ -11,6 +11,7 @@ import (
 type Cover struct {
 	k          int          //maximal size of cover
 	covered    map[int]int8 //map if each vertex is covered, and by how many edges
+	toCover    VertexSet    //the vertices that need to be covered
 	uncovered  int          //number of vertices that needs to be covered
 	InComp     []bool       //indicates for each Edge if its in comp. or not
 	inCompSel  int          //number of edges inComp already selected
@@ -25,6 +26,8 @@ type Cover struct {
 //NewCover acts as a constructor for Cover
 func NewCover(K int, vertices []int, bound Edges, compVertices []int) Cover {
 	covered := make(map[int]int8)
+	toCoverSet := NewVertexSet(vertices)
+	compSet := NewVertexSet(compVertices)
 
 	for _, v := range vertices {
 		covered[v] = 0
@@ -34,20 +37,9 @@ func NewCover(K int, vertices []int, bound Edges, compVertices []int) Cover {
 	inComp := make([]bool, len(bound.Slice()))
 
 	for i, e := range bound.Slice() {
-		sum := 0
-		for _, v := range e.Vertices {
-			_, ok := covered[v]
-			if ok {
-				sum++
-			} else {
-				if mem(compVertices, v) {
-					if !inComp[i] {
-						inComp[i] = true
-					}
-				}
-			}
-		}
-		toCover[i] = sum
+		set := e.Set()
+		toCover[i] = set.InterLen(toCoverSet)
+		inComp[i] = set.Diff(toCoverSet).Intersects(compSet)
 	}
 
 	sortBySliceEdge(bound.Slice(), toCover)
@@ -61,7 +53,7 @@ func NewCover(K int, vertices []int, bound Edges, compVertices []int) Cover {
 		covWeights[i] = sum
 	}
 
-	return Cover{k: K, covered: covered, uncovered: len(vertices),
+	return Cover{k: K, covered: covered, toCover: toCoverSet, uncovered: len(vertices),
 		InComp: inComp, covWeights: covWeights, bound: bound, pos: 0, HasNext: true, first: true}
 }
 
@@ -109,13 +101,7 @@ func (c *Cover) NextSubset() int {
 		selected := false
 
 		if c.InComp[c.pos] || c.inCompSel > 0 || len(c.Subset) < (c.k-1) {
-			for _, v := range c.bound.Slice()[c.pos].Vertices {
-				_, ok := c.covered[v]
-				if ok {
-					selected = true
-					break
-				}
-			}
+			selected = c.toCover.HasAny(c.bound.Slice()[c.pos].Vertices)
 		}
 
 		//Actually add it to the edge
@@ -126,9 +112,8 @@ func (c *Cover) NextSubset() int {
 			}
 
 			for _, v := range c.bound.Slice()[c.pos].Vertices {
-				val, ok := c.covered[v]
-				if ok {
-					if val == 0 {
+				if c.toCover.Has(v) {
+					if c.covered[v] == 0 {
 						c.uncovered--
 					}
 					c.covered[v]++
@@ -167,9 +152,8 @@ func (c *Cover) backtrack() bool {
 	}
 
 	for _, v := range c.bound.Slice()[c.pos].Vertices {
-		val, ok := c.covered[v]
-		if ok {
-			if val == 1 {
+		if c.toCover.Has(v) {
+			if c.covered[v] == 1 {
 				c.uncovered++
 			}
 			c.covered[v]--
@@ -3,6 +3,7 @@ package lib
 import (
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // A Decomp (short for Decomposition) consists of a labelled tree which
@@ -39,33 +40,35 @@ func (d Decomp) Correct(g Graph) bool {
 	//must be a decomp of same graph
 	if !d.Graph.equal(g) {
 		if d.Graph.Edges.Len() > 0 {
-			fmt.Println("Decomp of different graph")
+			fmt.Fprintln(messages, "Decomp of different graph")
 		} else {
-			fmt.Println("Empty Decomp")
+			fmt.Fprintln(messages, "Empty Decomp")
 		}
 		return false
 	}
 
 	//Every bag must be subset of the lambda label
 	if !d.Root.bagSubsets() {
-		fmt.Printf("Bags not subsets of edge labels")
+		fmt.Fprintf(messages, "Bags not subsets of edge labels")
 		return false
 	}
 
 	// Every edge has to be covered
+	bags := d.Root.bagSets(nil)
 	for _, e := range d.Graph.Edges.Slice() {
-		if !d.Root.coversEdge(e) {
-			fmt.Println("Edge ", e, " isn't covered")
+		if !e.coveredBy(bags) {
+			fmt.Fprintln(messages, "Edge ", e, " isn't covered")
 			return false
 		}
 	}
 
 	//connectedness
+	var violations VertexSet
+	d.Root.connected(VertexSet{}, &violations)
 	for _, i := range d.Graph.Edges.Vertices() {
-		nodeCheck, _ := d.Root.connected(i, false)
-		if !nodeCheck {
+		if violations.Has(i) {
 			mutex.RLock()
-			fmt.Printf("Vertex %v doesn't span connected subtree\n", m[i])
+			fmt.Fprintf(messages, "Vertex %v doesn't span connected subtree\n", m[i])
 			mutex.RUnlock()
 			return false
 		}
@@ -73,7 +76,7 @@ func (d Decomp) Correct(g Graph) bool {
 
 	//special condition (optionally)
 	if !d.Root.noSCViolation() {
-		fmt.Println("SCV found!. Not a valid hypertree decomposition!")
+		fmt.Fprintln(messages, "SCV found!. Not a valid hypertree decomposition!")
 	}
 
 	return true
@@ -101,3 +104,35 @@ func (d Decomp) CheckWidth() int {
 
 	return output
 }
+
+// TotalCost returns the sum of the join costs of all nodes in a decomp, i.e. the estimated cost of the query plan
+// it represents. Decomps produced without a cost model have a total cost of 0.
+func (d Decomp) TotalCost() float64 {
+	return d.Root.totalCost()
+}
+
+// SortDecomps orders decomps of disjoint subgraphs by the smallest edge of each subgraph (or of its special edges, if
+// it has no edges), so that combining them does not depend on the order in which they were computed
+func SortDecomps(decomps []Decomp) {
+	key := func(d Decomp) int {
+		min := -1
+		for _, e := range d.Graph.Edges.Slice() {
+			if min == -1 || e.Name < min {
+				min = e.Name
+			}
+		}
+		if min != -1 {
+			return min
+		}
+		for _, sp := range d.Graph.Special {
+			for _, e := range sp.Slice() {
+				if min == -1 || e.Name < min {
+					min = e.Name
+				}
+			}
+		}
+		return min
+	}
+
+	sort.SliceStable(decomps, func(i, j int) bool { return key(decomps[i]) < key(decomps[j]) })
+}
@@ -11,10 +11,38 @@ import (
 	"sync"
 )
 
-// An Edge (used here for hyperedge) consists of a collection of vertices and a name
+// An Edge (used here for hyperedge) consists of a collection of vertices and a name. Edges built by NewEdge also
+// store their vertices as a VertexSet, so the vertices must not be changed afterwards.
 type Edge struct {
 	Name     int
-	Vertices []int // use integers for vertices
+	Vertices []int     // use integers for vertices
+	set      VertexSet // the vertices as a set, empty unless built by NewEdge
+}
+
+// NewEdge is a constructor for Edge, storing the vertices both as a slice and as a set
+func NewEdge(name int, vertices []int) Edge {
+	return Edge{Name: name, Vertices: vertices, set: NewVertexSet(vertices)}
+}
+
+// Set returns the vertices of the edge as a VertexSet, which is only built here if the edge was not made by NewEdge
+func (e Edge) Set() VertexSet {
+	if e.set.words == nil && len(e.Vertices) > 0 {
+		return NewVertexSet(e.Vertices)
+	}
+	return e.set
+}
+
+// Equal checks if two edges have the same name and the same vertices, in the same order
+func (e Edge) Equal(o Edge) bool {
+	if e.Name != o.Name || len(e.Vertices) != len(o.Vertices) {
+		return false
+	}
+	for i := range e.Vertices {
+		if e.Vertices[i] != o.Vertices[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // FullString always prints the list of vertices of an edge, even if the edge is named
@@ -94,6 +122,9 @@ func (e *Edges) GobDecode(b []byte) error {
 	if err := decoder.Decode(&e.slice); err != nil {
 		return err
 	}
+	for i := range e.slice {
+		e.slice[i].set = NewVertexSet(e.slice[i].Vertices)
+	}
 
 	var hashMux sync.Mutex
 	e.hashMux = &hashMux
@@ -232,7 +263,7 @@ func (e Edge) subedges() []Edge {
 				subSet = append(subSet, elem)
 			}
 		}
-		output = append(output, Edge{Vertices: subSet})
+		output = append(output, NewEdge(0, subSet))
 		index++
 	}
 
@@ -279,11 +310,12 @@ func (e Edge) areNeighbours(o Edge) bool {
 	return false
 }
 
-func (e Edge) numNeighboursOrder(l Edges, remaining []bool) int {
+// numNeighboursOrder counts the remaining edges sharing a vertex with the set, which holds the vertices of an edge
+func numNeighboursOrder(set VertexSet, l Edges, remaining []bool) int {
 	output := 0
 
 	for i := range l.Slice() {
-		if remaining[i] && e.areNeighbours(l.Slice()[i]) {
+		if remaining[i] && set.Intersects(l.Slice()[i].Set()) {
 			output++
 		}
 	}
@@ -291,6 +323,11 @@ func (e Edge) numNeighboursOrder(l Edges, remaining []bool) int {
 	return output
 }
 
+// VertexSet returns the union of all vertices from a slice of Edge as a VertexSet
+func (e *Edges) VertexSet() VertexSet {
+	return NewVertexSet(e.Vertices())
+}
+
 // Vertices produces the union of all vertices from a slice of Edge
 func (e *Edges) Vertices() []int {
 
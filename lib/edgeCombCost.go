@@ -4,6 +4,11 @@ import (
 	"log"
 )
 
+// A CostModel associates costs to combinations of edges, identified by their names
+type CostModel interface {
+	Cost(edgeComb []int) float64
+}
+
 // An EdgesCostMap associates costs to combinations of edges
 type EdgesCostMap struct {
 	e2c map[uint64]float64
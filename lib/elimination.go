@@ -0,0 +1,422 @@
+package lib
+
+// elimination.go provides heuristics based on vertex elimination orderings of the primal graph, from which an
+// ordering of the edges is derived. Vertices eliminated last form the "center" of the graph, so edges containing
+// them are tried first when looking for separators.
+
+import (
+	"container/heap"
+	"math/rand"
+	"sort"
+)
+
+// primal computes the primal graph of the edges, as adjacency sets
+func primal(edges Edges) map[int]map[int]struct{} {
+	adj := make(map[int]map[int]struct{})
+
+	for _, e := range edges.Slice() {
+		for _, v := range e.Vertices {
+			if _, ok := adj[v]; !ok {
+				adj[v] = make(map[int]struct{})
+			}
+			for _, w := range e.Vertices {
+				if v != w {
+					adj[v][w] = Empty
+				}
+			}
+		}
+	}
+
+	return adj
+}
+
+// copyAdj produces a deep copy of an adjacency map
+func copyAdj(adj map[int]map[int]struct{}) map[int]map[int]struct{} {
+	output := make(map[int]map[int]struct{}, len(adj))
+
+	for v, neighbours := range adj {
+		output[v] = make(map[int]struct{}, len(neighbours))
+		for w := range neighbours {
+			output[v][w] = Empty
+		}
+	}
+
+	return output
+}
+
+// sortedVertices returns the vertices of an adjacency map in ascending order
+func sortedVertices(adj map[int]map[int]struct{}) []int {
+	var output []int
+	for v := range adj {
+		output = append(output, v)
+	}
+	sort.Ints(output)
+
+	return output
+}
+
+// eliminate removes v from the graph, turning its neighbourhood into a clique
+func eliminate(adj map[int]map[int]struct{}, v int) {
+	for w := range adj[v] {
+		delete(adj[w], v)
+		for u := range adj[v] {
+			if u != w {
+				adj[w][u] = Empty
+			}
+		}
+	}
+	delete(adj, v)
+}
+
+// fillIn counts the edges that eliminating v would add to the graph
+func fillIn(adj map[int]map[int]struct{}, v int) int {
+	var neighbours []int
+	for w := range adj[v] {
+		neighbours = append(neighbours, w)
+	}
+
+	output := 0
+	for i := range neighbours {
+		for j := i + 1; j < len(neighbours); j++ {
+			if _, ok := adj[neighbours[i]][neighbours[j]]; !ok {
+				output++
+			}
+		}
+	}
+
+	return output
+}
+
+// scoreEntry is an entry in a scoreQueue. It is outdated once the score of its vertex has changed.
+type scoreEntry struct {
+	score  int
+	tie    int
+	vertex int
+}
+
+// scoreQueue is a min-heap of vertices, ordered by their scores and then by their tie-breakers. Instead of updating
+// entries, a new entry is pushed whenever the score of a vertex changes, and outdated entries are skipped when popped.
+type scoreQueue []scoreEntry
+
+func (q scoreQueue) Len() int { return len(q) }
+
+func (q scoreQueue) Less(i, j int) bool {
+	if q[i].score != q[j].score {
+		return q[i].score < q[j].score
+	}
+	return q[i].tie < q[j].tie
+}
+
+func (q scoreQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *scoreQueue) Push(x interface{}) { *q = append(*q, x.(scoreEntry)) }
+
+func (q *scoreQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// tieBreaks ranks the vertices for breaking ties, by the given order and then by the smallest vertex, or in a random
+// order if r is not nil
+func tieBreaks(adj map[int]map[int]struct{}, r *rand.Rand, less func(v, w int) bool) map[int]int {
+	vertices := sortedVertices(adj)
+	if r != nil {
+		r.Shuffle(len(vertices), func(i, j int) { vertices[i], vertices[j] = vertices[j], vertices[i] })
+	} else {
+		sort.SliceStable(vertices, func(i, j int) bool { return less(vertices[i], vertices[j]) })
+	}
+
+	output := make(map[int]int, len(vertices))
+	for i, v := range vertices {
+		output[v] = i
+	}
+
+	return output
+}
+
+// greedyElimination repeatedly eliminates the vertex of least score, which is either its fill-in or its degree. Ties
+// are broken by the least degree in the original graph and then by the smallest vertex, or randomly if r is not nil.
+// After each elimination, only the scores affected by it are updated.
+func greedyElimination(adj map[int]map[int]struct{}, minFill bool, r *rand.Rand) []int {
+	tie := tieBreaks(adj, r, func(v, w int) bool { return len(adj[v]) < len(adj[w]) })
+	adj = copyAdj(adj)
+
+	score := func(v int) int {
+		if minFill {
+			return fillIn(adj, v)
+		}
+		return len(adj[v])
+	}
+
+	scores := make(map[int]int, len(adj))
+	queue := make(scoreQueue, 0, len(adj))
+	for v := range adj {
+		scores[v] = score(v)
+		queue = append(queue, scoreEntry{score: scores[v], tie: tie[v], vertex: v})
+	}
+	heap.Init(&queue)
+
+	var output []int
+	for len(adj) > 0 {
+		entry := heap.Pop(&queue).(scoreEntry)
+		v := entry.vertex
+		if _, ok := adj[v]; !ok || scores[v] != entry.score {
+			continue
+		}
+		output = append(output, v)
+
+		var neighbours []int
+		for w := range adj[v] {
+			neighbours = append(neighbours, w)
+		}
+
+		// each edge added between two neighbours of v lowers the fill-in of their common neighbours
+		changed := make(map[int]struct{})
+		if minFill {
+			for i := range neighbours {
+				for j := i + 1; j < len(neighbours); j++ {
+					x, y := neighbours[i], neighbours[j]
+					if _, ok := adj[x][y]; ok {
+						continue
+					}
+					if len(adj[x]) > len(adj[y]) {
+						x, y = y, x
+					}
+					for w := range adj[x] {
+						if _, ok := adj[y][w]; ok && w != v {
+							scores[w]--
+							changed[w] = Empty
+						}
+					}
+				}
+			}
+		}
+
+		eliminate(adj, v)
+
+		// the neighbourhoods of the neighbours of v changed entirely
+		for _, w := range neighbours {
+			scores[w] = score(w)
+			changed[w] = Empty
+		}
+		for w := range changed {
+			heap.Push(&queue, scoreEntry{score: scores[w], tie: tie[w], vertex: w})
+		}
+	}
+
+	return output
+}
+
+// minFillElimination produces a min-fill elimination ordering
+func minFillElimination(adj map[int]map[int]struct{}, r *rand.Rand) []int {
+	return greedyElimination(adj, true, r)
+}
+
+// minDegreeElimination produces a min-degree elimination ordering
+func minDegreeElimination(adj map[int]map[int]struct{}, r *rand.Rand) []int {
+	return greedyElimination(adj, false, r)
+}
+
+// mcsmElimination produces a minimal elimination ordering via MCS-M
+func mcsmElimination(adj map[int]map[int]struct{}, r *rand.Rand) []int {
+	order, _ := mcsm(adj, r)
+	return order
+}
+
+// mcsm computes a minimal elimination ordering via MCS-M (Berry et al. '04): vertices are numbered from last to
+// first, always picking an unnumbered vertex of maximum weight, and increasing the weight of every unnumbered vertex
+// u reachable via unnumbered vertices of weight less than that of u. Ties are broken by the greatest degree and then
+// by the smallest vertex, or randomly if r is not nil. It also returns the neighbours of each vertex in the produced
+// minimal triangulation that are eliminated after it.
+func mcsm(adj map[int]map[int]struct{}, r *rand.Rand) ([]int, map[int][]int) {
+	tie := tieBreaks(adj, r, func(v, w int) bool { return len(adj[v]) > len(adj[w]) })
+
+	// work on indices, with the neighbours in ascending order
+	vertices := sortedVertices(adj)
+	index := make(map[int]int, len(vertices))
+	for i, v := range vertices {
+		index[v] = i
+	}
+	neighbours := make([][]int, len(vertices))
+	for i, v := range vertices {
+		for w := range adj[v] {
+			neighbours[i] = append(neighbours[i], index[w])
+		}
+		sort.Ints(neighbours[i])
+	}
+
+	weight := make([]int, len(vertices))
+	numbered := make([]bool, len(vertices))
+	reached := make([]int, len(vertices)) // the last step that reached a vertex
+	buckets := make([][]int, len(vertices)+1)
+	order := make([]int, len(vertices))
+	madj := make(map[int][]int, len(vertices))
+
+	queue := make(scoreQueue, len(vertices))
+	for i, v := range vertices {
+		queue[i] = scoreEntry{score: 0, tie: tie[v], vertex: i}
+	}
+	heap.Init(&queue)
+
+	for step := len(vertices); step > 0; step-- {
+		var v int
+		for { // skip outdated entries
+			entry := heap.Pop(&queue).(scoreEntry)
+			if !numbered[entry.vertex] && -entry.score == weight[entry.vertex] {
+				v = entry.vertex
+				break
+			}
+		}
+		numbered[v] = true
+		reached[v] = step
+		order[step-1] = vertices[v]
+
+		// visit the unnumbered vertices in the order of the least maximum weight on a path from v, using a bucket for
+		// each weight. A vertex heavier than the path reaching it is increased.
+		var increased []int
+		top := 0
+		for _, w := range neighbours[v] {
+			if !numbered[w] {
+				reached[w] = step
+				buckets[weight[w]] = append(buckets[weight[w]], w)
+				increased = append(increased, w)
+				if weight[w] > top {
+					top = weight[w]
+				}
+			}
+		}
+		for j := 0; j <= top; j++ {
+			for len(buckets[j]) > 0 {
+				y := buckets[j][len(buckets[j])-1]
+				buckets[j] = buckets[j][:len(buckets[j])-1]
+
+				for _, z := range neighbours[y] {
+					if numbered[z] || reached[z] == step {
+						continue
+					}
+					reached[z] = step
+					if weight[z] > j {
+						buckets[weight[z]] = append(buckets[weight[z]], z)
+						increased = append(increased, z)
+						if weight[z] > top {
+							top = weight[z]
+						}
+					} else {
+						buckets[j] = append(buckets[j], z)
+					}
+				}
+			}
+		}
+
+		for _, u := range increased {
+			weight[u]++
+			madj[vertices[u]] = append(madj[vertices[u]], vertices[v])
+			heap.Push(&queue, scoreEntry{score: -weight[u], tie: tie[vertices[u]], vertex: u})
+		}
+	}
+
+	return order, madj
+}
+
+// eliminationWidth computes the width of the tree decomposition induced by an elimination ordering, i.e. the
+// largest number of neighbours of a vertex at the time of its elimination
+func eliminationWidth(adj map[int]map[int]struct{}, order []int) int {
+	adj = copyAdj(adj)
+	output := 0
+
+	for _, v := range order {
+		if len(adj[v]) > output {
+			output = len(adj[v])
+		}
+		eliminate(adj, v)
+	}
+
+	return output
+}
+
+// orderByElimination sorts the edges by the latest elimination of their vertices, and then by the sum of the
+// positions of their vertices, both descending
+func orderByElimination(edges Edges, order []int) Edges {
+	position := make(map[int]int, len(order))
+	for i, v := range order {
+		position[v] = i
+	}
+
+	slice := make([]Edge, edges.Len())
+	copy(slice, edges.Slice())
+	latest := make(map[int]int, len(slice))
+	sum := make(map[int]int, len(slice))
+	keys := make([]int, len(slice))
+	for i, e := range slice {
+		keys[i] = i
+		for _, v := range e.Vertices {
+			if position[v] > latest[i] {
+				latest[i] = position[v]
+			}
+			sum[i] = sum[i] + position[v]
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if latest[keys[i]] != latest[keys[j]] {
+			return latest[keys[i]] > latest[keys[j]]
+		}
+		return sum[keys[i]] > sum[keys[j]]
+	})
+
+	output := make([]Edge, len(slice))
+	for i, k := range keys {
+		output[i] = slice[k]
+	}
+
+	return NewEdges(output)
+}
+
+// bestElimination runs an elimination heuristic once with deterministic tie-breaking, and then restarts many times
+// with random tie-breaking, returning the ordering of least width
+func bestElimination(edges Edges, restarts int,
+	heuristic func(map[int]map[int]struct{}, *rand.Rand) []int) []int {
+	adj := primal(edges)
+
+	best := heuristic(adj, nil)
+	bestWidth := eliminationWidth(adj, best)
+
+	r := newRand()
+	for i := 0; i < restarts; i++ {
+		order := heuristic(adj, r)
+		if width := eliminationWidth(adj, order); width < bestWidth {
+			best, bestWidth = order, width
+		}
+	}
+
+	return best
+}
+
+// GetMinFillOrder orders the edges based on a min-fill elimination ordering of the primal graph, keeping the best of
+// the given number of randomized restarts
+func GetMinFillOrder(edges Edges, restarts int) Edges {
+	if edges.Len() <= 1 {
+		return edges
+	}
+	return orderByElimination(edges, bestElimination(edges, restarts, minFillElimination))
+}
+
+// GetMinDegreeOrder orders the edges based on a min-degree elimination ordering of the primal graph, keeping the best
+// of the given number of randomized restarts
+func GetMinDegreeOrder(edges Edges, restarts int) Edges {
+	if edges.Len() <= 1 {
+		return edges
+	}
+	return orderByElimination(edges, bestElimination(edges, restarts, minDegreeElimination))
+}
+
+// GetMCSMOrder orders the edges based on an MCS-M elimination ordering of the primal graph, keeping the best of the
+// given number of randomized restarts
+func GetMCSMOrder(edges Edges, restarts int) Edges {
+	if edges.Len() <= 1 {
+		return edges
+	}
+	return orderByElimination(edges, bestElimination(edges, restarts, mcsmElimination))
+}
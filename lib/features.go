@@ -0,0 +1,223 @@
+package lib
+
+// features.go computes features of hypergraphs, and uses them to choose an algorithm via a decision tree
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Features collects structural properties of a hypergraph, useful to predict which algorithm performs best on it
+type Features struct {
+	Vertices        int     `json:"vertices"`
+	Edges           int     `json:"edges"`
+	AvgArity        float64 `json:"avgArity"`
+	MaxArity        int     `json:"maxArity"`
+	SumArity        int     `json:"sumArity"`
+	AvgDegree       float64 `json:"avgDegree"`
+	MaxDegree       int     `json:"maxDegree"`
+	DegreeStdDev    float64 `json:"degreeStdDev"`
+	BIP             int     `json:"bip"`
+	Hinges          int     `json:"hinges"`
+	ReducedVertices int     `json:"reducedVertices"` // after the GYÖ reduction
+	ReducedEdges    int     `json:"reducedEdges"`    // after the GYÖ reduction
+}
+
+// GetFeatures computes the features of a graph
+func GetFeatures(g Graph) Features {
+	var output Features
+
+	output.Vertices = len(g.Edges.Vertices())
+	output.Edges = g.Edges.Len()
+
+	degrees := make(map[int]int)
+	for _, e := range g.Edges.Slice() {
+		output.SumArity = output.SumArity + len(e.Vertices)
+		if len(e.Vertices) > output.MaxArity {
+			output.MaxArity = len(e.Vertices)
+		}
+		for _, v := range e.Vertices {
+			degrees[v]++
+		}
+	}
+
+	if output.Edges > 0 {
+		output.AvgArity = float64(output.SumArity) / float64(output.Edges)
+	}
+
+	if len(degrees) > 0 {
+		sum := 0
+		for _, d := range degrees {
+			sum = sum + d
+			if d > output.MaxDegree {
+				output.MaxDegree = d
+			}
+		}
+		output.AvgDegree = float64(sum) / float64(len(degrees))
+
+		variance := 0.0
+		for _, d := range degrees {
+			variance = variance + (float64(d)-output.AvgDegree)*(float64(d)-output.AvgDegree)
+		}
+		output.DegreeStdDev = math.Sqrt(variance / float64(len(degrees)))
+	}
+
+	output.BIP = g.GetBIP()
+
+	reduced, _ := g.GYÖReduct()
+	output.ReducedVertices = len(reduced.Edges.Vertices())
+	output.ReducedEdges = reduced.Edges.Len()
+
+	if output.Edges > 0 {
+		output.Hinges = GetHingeTree(g).Len()
+	}
+
+	return output
+}
+
+// Values maps the names of all features to their values
+func (f Features) Values() map[string]float64 {
+	return map[string]float64{
+		"vertices":        float64(f.Vertices),
+		"edges":           float64(f.Edges),
+		"avgArity":        f.AvgArity,
+		"maxArity":        float64(f.MaxArity),
+		"sumArity":        float64(f.SumArity),
+		"avgDegree":       f.AvgDegree,
+		"maxDegree":       float64(f.MaxDegree),
+		"degreeStdDev":    f.DegreeStdDev,
+		"bip":             float64(f.BIP),
+		"hinges":          float64(f.Hinges),
+		"reducedVertices": float64(f.ReducedVertices),
+		"reducedEdges":    float64(f.ReducedEdges),
+	}
+}
+
+func (f Features) String() string {
+	values := f.Values()
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%v: %v", name, values[name]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// maxHeuristic is the largest edge ordering heuristic which can be chosen
+const maxHeuristic = 7
+
+// A Choice is a configuration of algorithm, edge ordering heuristic and (for the hybrid algorithms) depth
+type Choice struct {
+	Algorithm string `json:"algorithm"` // one of local, global, det, balDet, seqBalDet
+	Heuristic int    `json:"heuristic"` // the edge ordering, 0 if none
+	Depth     int    `json:"depth"`     // the depth for the hybrid algorithms
+}
+
+func (c Choice) String() string {
+	if c.Algorithm == "balDet" || c.Algorithm == "seqBalDet" {
+		return fmt.Sprintf("%v (depth %v), heuristic %v", c.Algorithm, c.Depth, c.Heuristic)
+	}
+	return fmt.Sprintf("%v, heuristic %v", c.Algorithm, c.Heuristic)
+}
+
+// A DecisionTree chooses a configuration based on the features of a graph. Inner nodes compare a feature against a
+// threshold, leaves hold the choice.
+type DecisionTree struct {
+	Feature   string        `json:"feature,omitempty"`
+	Threshold float64       `json:"threshold,omitempty"`
+	Below     *DecisionTree `json:"below,omitempty"` // taken if the feature is below the threshold
+	Above     *DecisionTree `json:"above,omitempty"` // taken otherwise
+	Choice    *Choice       `json:"choice,omitempty"`
+}
+
+// DefaultDecisionTree is used if no other decision tree is given. It is a hand-written placeholder, not trained on
+// any benchmark results, and its thresholds are only rough guesses: acyclic and small graphs go to DetK, graphs with
+// small intersections between edges to the hybrid algorithm, and all others to BalSep Local. The expensive max.
+// separator ordering is only used on graphs with few vertices. A tree derived from actual measurements should be
+// passed via the autoRules flag instead.
+const DefaultDecisionTree = `{
+	"feature": "reducedEdges", "threshold": 1,
+	"below": {"choice": {"algorithm": "det", "heuristic": 0}},
+	"above": {
+		"feature": "edges", "threshold": 50,
+		"below": {"choice": {"algorithm": "det", "heuristic": 1}},
+		"above": {
+			"feature": "bip", "threshold": 3,
+			"below": {"choice": {"algorithm": "balDet", "heuristic": 1, "depth": 1}},
+			"above": {
+				"feature": "vertices", "threshold": 500,
+				"below": {"choice": {"algorithm": "local", "heuristic": 2}},
+				"above": {"choice": {"algorithm": "local", "heuristic": 1}}
+			}
+		}
+	}
+}`
+
+// GetDecisionTree parses a decision tree from JSON, checking that each node is either a leaf or compares a known
+// feature
+func GetDecisionTree(data []byte) (*DecisionTree, error) {
+	var tree DecisionTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	if err := tree.validate(Features{}.Values()); err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+func (d *DecisionTree) validate(known map[string]float64) error {
+	if d.Choice != nil {
+		switch d.Choice.Algorithm {
+		case "local", "global", "det":
+		case "balDet", "seqBalDet":
+			if d.Choice.Depth < 1 {
+				return fmt.Errorf("depth %v for %v, must be at least 1", d.Choice.Depth, d.Choice.Algorithm)
+			}
+		default:
+			return fmt.Errorf("unknown algorithm %v", d.Choice.Algorithm)
+		}
+		if d.Choice.Heuristic < 0 || d.Choice.Heuristic > maxHeuristic {
+			return fmt.Errorf("unknown heuristic %v, must be between 0 and %v", d.Choice.Heuristic, maxHeuristic)
+		}
+		return nil
+	}
+
+	if _, ok := known[d.Feature]; !ok {
+		return fmt.Errorf("unknown feature %v", d.Feature)
+	}
+	if d.Below == nil || d.Above == nil {
+		return fmt.Errorf("missing branch for feature %v", d.Feature)
+	}
+	if err := d.Below.validate(known); err != nil {
+		return err
+	}
+	return d.Above.validate(known)
+}
+
+// Decide follows the decision tree for the given features, returning the choice at the leaf reached
+func (d *DecisionTree) Decide(f Features) Choice {
+	values := f.Values()
+
+	current := d
+	for current.Choice == nil {
+		if values[current.Feature] < current.Threshold {
+			current = current.Below
+		} else {
+			current = current.Above
+		}
+	}
+
+	return *current.Choice
+}
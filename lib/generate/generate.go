@@ -0,0 +1,342 @@
+// Package generate produces random and structured hypergraphs, to be used in tests and benchmarks. All random
+// generators draw from a given source of randomness, so that generated instances can be reproduced from a seed, and
+// the results can be written out in HyperBench or PACE 2019 format.
+package generate
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// A Hypergraph is a generated hypergraph, whose vertices are encoded as the integers starting from 0
+type Hypergraph struct {
+	Names []string // the names of the edges
+	Edges [][]int  // the vertices of each edge
+}
+
+// add appends an edge with the given vertices, named after its position unless a name is given
+func (h *Hypergraph) add(name string, vertices []int) {
+	if name == "" {
+		name = "E" + strconv.Itoa(len(h.Edges)+1)
+	}
+	h.Names = append(h.Names, name)
+	h.Edges = append(h.Edges, vertices)
+}
+
+// NumVertices returns the number of vertices, i.e. one more than the largest vertex in any edge
+func (h Hypergraph) NumVertices() int {
+	output := 0
+	for _, e := range h.Edges {
+		for _, v := range e {
+			if v+1 > output {
+				output = v + 1
+			}
+		}
+	}
+	return output
+}
+
+// shuffle randomly reorders the edges and relabels the vertices, so that the structure of a generated hypergraph
+// is not given away by the order of its edges or vertices. Edges keep their names.
+func (h *Hypergraph) shuffle(r *rand.Rand) {
+	perm := r.Perm(h.NumVertices())
+	for _, e := range h.Edges {
+		for i := range e {
+			e[i] = perm[e[i]]
+		}
+		sort.Ints(e)
+	}
+
+	r.Shuffle(len(h.Edges), func(i, j int) {
+		h.Edges[i], h.Edges[j] = h.Edges[j], h.Edges[i]
+		h.Names[i], h.Names[j] = h.Names[j], h.Names[i]
+	})
+}
+
+// HyperBench returns the hypergraph in HyperBench format, naming the vertex i as Vi
+func (h Hypergraph) HyperBench() string {
+	var buffer bytes.Buffer
+
+	for i, e := range h.Edges {
+		buffer.WriteString(h.Names[i] + "(")
+		for j, v := range e {
+			if j > 0 {
+				buffer.WriteString(",")
+			}
+			buffer.WriteString("V" + strconv.Itoa(v))
+		}
+		buffer.WriteString(")")
+		if i != len(h.Edges)-1 {
+			buffer.WriteString(",\n")
+		}
+	}
+	buffer.WriteString(".\n")
+
+	return buffer.String()
+}
+
+// PACE returns the hypergraph in PACE 2019 format, where edges are numbered by their position and the vertex i
+// becomes i+1. Names of edges are not part of this format.
+func (h Hypergraph) PACE() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("p htd " + strconv.Itoa(h.NumVertices()) + " " + strconv.Itoa(len(h.Edges)) + "\n")
+	for i, e := range h.Edges {
+		buffer.WriteString(strconv.Itoa(i + 1))
+		for _, v := range e {
+			buffer.WriteString(" " + strconv.Itoa(v+1))
+		}
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+// pick draws size distinct vertices using the given draw function, which produces single vertices out of n
+func pick(n, size int, draw func() int, r *rand.Rand) []int {
+	if size > n {
+		size = n
+	}
+	chosen := make(map[int]struct{}, size)
+	output := make([]int, 0, size)
+
+	for attempts := 0; len(output) < size; attempts++ {
+		v := draw()
+		if attempts > 100*size { // the draw is too skewed to find fresh vertices, fall back to uniform choices
+			v = r.Intn(n)
+		}
+		if _, ok := chosen[v]; ok {
+			continue
+		}
+		chosen[v] = struct{}{}
+		output = append(output, v)
+	}
+
+	sort.Ints(output)
+	return output
+}
+
+// Uniform produces a random hypergraph with the given number of edges over the given number of vertices, where
+// each edge consists of arity vertices chosen uniformly at random. The hypergraph is empty unless the number of
+// vertices and the arity are positive.
+func Uniform(r *rand.Rand, vertices, edges, arity int) Hypergraph {
+	var output Hypergraph
+	if vertices < 1 || arity < 1 {
+		return output
+	}
+
+	draw := func() int { return r.Intn(vertices) }
+	for i := 0; i < edges; i++ {
+		output.add("", pick(vertices, arity, draw, r))
+	}
+
+	return output
+}
+
+// PowerLaw produces a random hypergraph like Uniform, except that the vertices are chosen with a probability
+// following a power law, so that the vertex i has a weight of (i+1)^(-exponent). This leads to few vertices of very
+// high degree, and many of low degree. As for Uniform, the hypergraph is empty unless the number of vertices and the
+// arity are positive.
+func PowerLaw(r *rand.Rand, vertices, edges, arity int, exponent float64) Hypergraph {
+	var output Hypergraph
+	if vertices < 1 || arity < 1 {
+		return output
+	}
+
+	cumulative := make([]float64, vertices)
+	total := 0.0
+	for i := range cumulative {
+		total += math.Pow(float64(i+1), -exponent)
+		cumulative[i] = total
+	}
+
+	draw := func() int {
+		v := sort.SearchFloat64s(cumulative, r.Float64()*total)
+		if v >= vertices {
+			v = vertices - 1
+		}
+		return v
+	}
+	for i := 0; i < edges; i++ {
+		output.add("", pick(vertices, arity, draw, r))
+	}
+	output.shuffle(r)
+
+	return output
+}
+
+// Grid produces the grid graph with the given number of rows and columns, with a binary edge between any two
+// neighbouring vertices
+func Grid(rows, cols int) Hypergraph {
+	var output Hypergraph
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			v := i*cols + j
+			if j+1 < cols {
+				output.add("", []int{v, v + 1})
+			}
+			if i+1 < rows {
+				output.add("", []int{v, v + cols})
+			}
+		}
+	}
+
+	return output
+}
+
+// Cycle produces the cycle over n vertices, which has a hypertree width of 2. The hypergraph is empty for n < 3, as
+// there is no cycle without self-loops or parallel edges on fewer vertices.
+func Cycle(n int) Hypergraph {
+	var output Hypergraph
+	if n < 3 {
+		return output
+	}
+
+	for i := 0; i < n; i++ {
+		output.add("", []int{i, (i + 1) % n})
+	}
+
+	return output
+}
+
+// Clique produces the complete graph over n vertices, which has a hypertree width of ⌈n/2⌉
+func Clique(n int) Hypergraph {
+	var output Hypergraph
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			output.add("", []int{i, j})
+		}
+	}
+
+	return output
+}
+
+// HypertreeWidth produces a random hypergraph with a hypertree width and generalized hypertree width of exactly k,
+// whose edges have up to arity vertices (but at least 2).
+//
+// The hypergraph is built along a random HD of width k with the given number of nodes. The root covers a clique of
+// 2k vertices with a perfect matching of it, and every other node introduces up to k new edges, which share vertices
+// with its parent and add fresh ones. No edge shares more than two vertices with the clique, so any bag containing the
+// whole clique, which every GHD must have, needs at least k edges to be covered.
+func HypertreeWidth(r *rand.Rand, k, nodes, arity int) Hypergraph {
+	var output Hypergraph
+	if arity < 2 {
+		arity = 2
+	}
+
+	clique := Clique(2 * k)
+	for _, e := range clique.Edges {
+		output.add("", e)
+	}
+
+	inClique := func(v int) bool { return v < 2*k }
+	fresh := 2 * k
+
+	root := make([]int, 2*k)
+	for i := range root {
+		root[i] = i
+	}
+
+	bags := [][]int{root}
+	for t := 1; t < nodes; t++ {
+		parent := bags[r.Intn(len(bags))]
+		var bag []int
+
+		for i := 1 + r.Intn(k); i > 0; i-- {
+			size := 2 + r.Intn(arity-1)
+			shared := r.Intn(size)
+			if len(bag) == 0 && shared == 0 {
+				shared = 1 // make sure the node is connected to its parent
+			}
+
+			var edge []int
+			fromClique := 0
+			for _, j := range r.Perm(len(parent)) {
+				if len(edge) == shared {
+					break
+				}
+				v := parent[j]
+				if inClique(v) {
+					if fromClique == 2 {
+						continue
+					}
+					fromClique++
+				}
+				edge = append(edge, v)
+			}
+			for len(edge) < size {
+				edge = append(edge, fresh)
+				fresh++
+			}
+
+			sort.Ints(edge)
+			output.add("", edge)
+			bag = append(bag, edge...)
+		}
+
+		bags = append(bags, dedup(bag))
+	}
+	output.shuffle(r)
+
+	return output
+}
+
+// CQ produces the hypergraph of a random conjunctive query with the given number of atoms over the given number of
+// variables. Each atom has a random arity between 1 and arity, and all but the first atom share a variable with an
+// earlier one, so that the query is connected. Atoms are named R1, R2, ... The hypergraph is empty unless the number
+// of variables and the arity are positive.
+func CQ(r *rand.Rand, atoms, variables, arity int) Hypergraph {
+	var output Hypergraph
+	if variables < 1 || arity < 1 {
+		return output
+	}
+	var used []int
+
+	draw := func() int { return r.Intn(variables) }
+	for i := 0; i < atoms; i++ {
+		size := 1 + r.Intn(arity)
+		atom := pick(variables, size, draw, r)
+
+		if len(used) > 0 {
+			join := used[r.Intn(len(used))]
+			found := false
+			for _, v := range atom {
+				if v == join {
+					found = true
+				}
+			}
+			if !found {
+				atom[r.Intn(len(atom))] = join
+				atom = dedup(atom)
+			}
+		}
+
+		for _, v := range atom {
+			used = append(used, v)
+		}
+		output.add("R"+strconv.Itoa(i+1), atom)
+	}
+
+	return output
+}
+
+// dedup sorts a slice of vertices and removes duplicates from it
+func dedup(vertices []int) []int {
+	sort.Ints(vertices)
+
+	j := 0
+	for i := 1; i < len(vertices); i++ {
+		if vertices[j] == vertices[i] {
+			continue
+		}
+		j++
+		vertices[j] = vertices[i]
+	}
+
+	return vertices[:j+1]
+}
@@ -99,150 +99,6 @@ func (g Graph) GetSubset(s []int) Edges {
 	return GetSubset(g.Edges, s)
 }
 
-// GetComponents uses Disjoint Set data structure to compute connected components
-func (g Graph) GetComponents(sep Edges, vertices map[int]*disjoint.Element) ([]Graph, map[int]int, []Edge) {
-	var outputG []Graph
-
-	// var vertices = make(map[int]*disjoint.Element, len(g.Vertices()))
-	var comps = make(map[*disjoint.Element][]Edge)
-	var compsSp = make(map[*disjoint.Element][]Edges)
-
-	balsepVert := sep.Vertices()
-	// var balSepCache
-
-	balSepCache := make([]bool, encode)
-	for _, v := range balsepVert {
-		balSepCache[v-1] = true
-	}
-
-	//  Set up the disjoint sets for each node
-	for _, i := range g.Vertices() {
-		if e, ok := vertices[i]; ok {
-			e.Reset()
-		} else {
-			vertices[i] = disjoint.NewElement()
-		}
-	}
-
-	// Merge together the connected components
-	for k := range g.Edges.Slice() {
-		for i := 0; i < len(g.Edges.Slice()[k].Vertices); i++ {
-			if balSepCache[g.Edges.Slice()[k].Vertices[i]-1] {
-				continue
-			}
-			for j := i + 1; j < len(g.Edges.Slice()[k].Vertices); j++ {
-				if balSepCache[g.Edges.Slice()[k].Vertices[j]-1] {
-					continue
-				}
-
-				disjoint.Union(vertices[g.Edges.Slice()[k].Vertices[i]], vertices[g.Edges.Slice()[k].Vertices[j]])
-				i = j - 1
-				break
-			}
-		}
-	}
-
-	for k := range g.Special {
-		for i := 0; i < len(g.Special[k].Vertices())-1; i++ {
-			if balSepCache[g.Special[k].Vertices()[i]-1] {
-				continue
-			}
-			for j := i + 1; j < len(g.Special[k].Vertices()); j++ {
-				if balSepCache[g.Special[k].Vertices()[j]-1] {
-					continue
-				}
-				disjoint.Union(vertices[g.Special[k].Vertices()[i]], vertices[g.Special[k].Vertices()[j]])
-				i = j - 1
-				break
-			}
-		}
-	}
-
-	var isolatedEdges []Edge
-
-	//sort each edge and special edge to a corresponding component
-	for i := range g.Edges.Slice() {
-		var vertexRep int
-		found := false
-		for _, v := range g.Edges.Slice()[i].Vertices {
-			if balSepCache[v-1] {
-				continue
-			}
-			vertexRep = v
-			found = true
-			break
-		}
-		if !found {
-			isolatedEdges = append(isolatedEdges, g.Edges.Slice()[i])
-			continue
-		}
-
-		slice, ok := comps[vertices[vertexRep].Find()]
-		if !ok {
-			newslice := make([]Edge, 0, g.Edges.Len())
-			comps[vertices[vertexRep].Find()] = newslice
-			slice = newslice
-		}
-
-		comps[vertices[vertexRep].Find()] = append(slice, g.Edges.Slice()[i])
-	}
-
-	var isolatedSp []Edges
-	for i := range g.Special {
-		var vertexRep int
-		found := false
-		for _, v := range g.Special[i].Vertices() {
-			if balSepCache[v-1] {
-				continue
-			}
-			vertexRep = v
-			found = true
-			break
-		}
-		if !found {
-			isolatedSp = append(isolatedSp, g.Special[i])
-			continue
-		}
-
-		slice, ok := compsSp[vertices[vertexRep].Find()]
-		if !ok {
-			newslice := make([]Edges, 0, len(g.Special))
-			compsSp[vertices[vertexRep].Find()] = newslice
-			slice = newslice
-		}
-
-		compsSp[vertices[vertexRep].Find()] = append(slice, g.Special[i])
-	}
-
-	edgeToComp := make(map[int]int)
-
-	// Store the components as graphs
-	for k := range comps {
-		slice := comps[k]
-		for i := range slice {
-			edgeToComp[slice[i].Name] = len(outputG)
-		}
-		g := Graph{Edges: NewEdges(slice), Special: compsSp[k]}
-		outputG = append(outputG, g)
-	}
-
-	for k := range compsSp {
-		_, ok := comps[k]
-		if ok {
-			continue
-		}
-		g := Graph{Edges: NewEdges([]Edge{}), Special: compsSp[k]}
-		outputG = append(outputG, g)
-	}
-
-	for i := range isolatedSp {
-		g := Graph{Edges: NewEdges([]Edge{}), Special: []Edges{isolatedSp[i]}}
-		outputG = append(outputG, g)
-	}
-
-	return outputG, edgeToComp, isolatedEdges
-}
-
 func (d *DSD) Update(e Edge) {
 
 	for i := 0; i < len(e.Vertices); i++ {
@@ -266,9 +122,10 @@ func (d *DSD) Update(e Edge) {
 // Edges are only removed, if they have an empty intersection with the vertex set.
 func FilterVertices(edges Edges, vertices []int) Edges {
 	var output []Edge
+	set := NewVertexSet(vertices)
 
 	for _, e := range edges.Slice() {
-		if len(Inter(e.Vertices, vertices)) > 0 {
+		if e.Set().Intersects(set) {
 			output = append(output, e)
 		}
 	}
@@ -280,9 +137,10 @@ func FilterVertices(edges Edges, vertices []int) Edges {
 // Edges are removed if they are not full subsets of the vertex set
 func FilterVerticesStrict(edges Edges, vertices []int) Edges {
 	var output []Edge
+	set := NewVertexSet(vertices)
 
 	for _, e := range edges.Slice() {
-		if Subset(e.Vertices, vertices) {
+		if e.Set().SubsetOf(set) {
 			output = append(output, e)
 		}
 	}
@@ -295,12 +153,21 @@ func FilterVerticesStrict(edges Edges, vertices []int) Edges {
 // producing the induced subgraph
 func CutEdges(edges Edges, vertices []int) Edges {
 	var output []Edge
+	set := NewVertexSet(vertices)
 
 	for i := range edges.Slice() {
-		inter := Inter(edges.Slice()[i].Vertices, vertices)
+		if !edges.Slice()[i].Set().Intersects(set) {
+			continue
+		}
+		var inter []int
+		for _, v := range edges.Slice()[i].Vertices {
+			if set.Has(v) {
+				inter = append(inter, v)
+			}
+		}
 		if len(inter) > 0 {
 			name := edges.Slice()[i].Name
-			output = append(output, Edge{Name: name, Vertices: inter})
+			output = append(output, NewEdge(name, inter))
 		}
 	}
 
@@ -380,9 +247,8 @@ func (g *Graph) MakeEdgesDistinct() []int {
 	newEdges := []Edge{}
 
 	for _, e := range g.Edges.Slice() {
-		e.Vertices = append(e.Vertices, encode)
 		tmp = append(tmp, encode)
-		newEdges = append(newEdges, e)
+		newEdges = append(newEdges, NewEdge(e.Name, append(e.Vertices, encode)))
 		encode++
 	}
 
@@ -392,13 +258,12 @@ func (g *Graph) MakeEdgesDistinct() []int {
 }
 
 func (n *Node) RemoveVertices(vertices []int) {
-	n.Bag = Diff(n.Bag, vertices)
+	n.setBag(Diff(n.Bag, vertices))
 
 	nuEdges := []Edge{}
 
 	for _, e := range n.Cover.Slice() {
-		e.Vertices = Diff(e.Vertices, vertices)
-		nuEdges = append(nuEdges, e)
+		nuEdges = append(nuEdges, NewEdge(e.Name, Diff(e.Vertices, vertices)))
 	}
 
 	n.Cover = NewEdges(nuEdges)
@@ -5,6 +5,8 @@ package lib
 import (
 	"encoding/binary"
 	"hash/fnv"
+	"reflect"
+	"sort"
 )
 
 // IntHash computes a hash for slices of integers
@@ -90,3 +92,155 @@ func (g *Graph) Hash() uint64 {
 
 	return output
 }
+
+// hashInts computes an order-sensitive hash for a slice of integers
+func hashInts(values []int) uint64 {
+	h := fnv.New64a()
+	bs := make([]byte, 8)
+
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(bs, uint64(v))
+		h.Write(bs)
+	}
+
+	return h.Sum64()
+}
+
+// hashUints computes an order-sensitive hash for a slice of hashes
+func hashUints(values []uint64) uint64 {
+	h := fnv.New64a()
+	bs := make([]byte, 8)
+
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(bs, v)
+		h.Write(bs)
+	}
+
+	return h.Sum64()
+}
+
+// CanonicalHash computes a (non-cryptographic) hash of a decomp, which is the same for all rerootings of the
+// decomp, for all orders of children and after merging any node into a neighbour whose bag contains its own.
+// Nodes are distinguished by their bags and the names of the edges in their covers.
+func (d Decomp) CanonicalHash() uint64 {
+	if reflect.DeepEqual(d, Decomp{}) {
+		return 0
+	}
+
+	// build an undirected tree
+	var bags [][]int
+	var labels []uint64
+	var adj []map[int]struct{}
+
+	var build func(n Node) int
+	build = func(n Node) int {
+		id := len(bags)
+
+		bag := make([]int, len(n.Bag))
+		copy(bag, n.Bag)
+		bag = RemoveDuplicates(bag)
+
+		var names []int
+		for _, e := range n.Cover.Slice() {
+			names = append(names, e.Name)
+		}
+		sort.Ints(names)
+
+		bags = append(bags, bag)
+		labels = append(labels, hashUints([]uint64{hashInts(bag), hashInts(names)}))
+		adj = append(adj, make(map[int]struct{}))
+
+		for _, c := range n.Children {
+			child := build(c)
+			adj[id][child] = Empty
+			adj[child][id] = Empty
+		}
+
+		return id
+	}
+	build(d.Root)
+
+	// merge nodes into neighbours containing their bags, until none are left
+	removed := make([]bool, len(bags))
+	merged := true
+	for merged {
+		merged = false
+		for i := range bags {
+			if removed[i] {
+				continue
+			}
+			for j := range adj[i] {
+				if !Subset(bags[i], bags[j]) {
+					continue
+				}
+				if len(bags[i]) == len(bags[j]) && labels[i] > labels[j] {
+					continue // equal bags: keep the node with the larger label, independent of the tree shape
+				}
+
+				removed[i] = true
+				delete(adj[j], i)
+				for k := range adj[i] {
+					if k != j {
+						delete(adj[k], i)
+						adj[k][j] = Empty
+						adj[j][k] = Empty
+					}
+				}
+				adj[i] = make(map[int]struct{})
+				merged = true
+				break
+			}
+		}
+	}
+
+	// find the center(s) of the tree by removing leaves
+	degree := make([]int, len(bags))
+	var leaves []int
+	remaining := 0
+	for i := range bags {
+		if removed[i] {
+			continue
+		}
+		remaining++
+		degree[i] = len(adj[i])
+		if degree[i] <= 1 {
+			leaves = append(leaves, i)
+		}
+	}
+	for remaining > 2 {
+		var next []int
+		for _, l := range leaves {
+			remaining--
+			for k := range adj[l] {
+				degree[k]--
+				if degree[k] == 1 {
+					next = append(next, k)
+				}
+			}
+		}
+		leaves = next
+	}
+
+	var rooted func(v, parent int) uint64
+	rooted = func(v, parent int) uint64 {
+		var children []uint64
+		for k := range adj[v] {
+			if k != parent {
+				children = append(children, rooted(k, v))
+			}
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+
+		return hashUints(append([]uint64{labels[v]}, children...))
+	}
+
+	if len(leaves) == 1 {
+		return rooted(leaves[0], -1)
+	}
+
+	first, second := rooted(leaves[0], leaves[1]), rooted(leaves[1], leaves[0])
+	if first > second {
+		first, second = second, first
+	}
+	return hashUints([]uint64{first, second})
+}
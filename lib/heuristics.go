@@ -4,24 +4,26 @@ package lib
 // computation of hypergraph decompositions
 
 import (
-	"math"
-	"math/rand"
+	"runtime"
 	"sort"
-	"time"
+	"sync"
 )
 
 // GetMSCOrder produces the Maximal Cardinality Search Ordering.
 // Implementation is based det-k-decomp of Samer and Gottlob '09
 func GetMSCOrder(edges Edges) Edges {
-	rand.Seed(time.Now().UTC().UnixNano())
 	if edges.Len() <= 1 {
 		return edges
 	}
 	var selected []Edge
 	chosen := make([]bool, edges.Len())
+	sets := make([]VertexSet, edges.Len())
+	for i := range edges.Slice() {
+		sets[i] = edges.Slice()[i].Set()
+	}
 
 	//randomly select last edge in the ordering
-	i := rand.Intn(edges.Len())
+	i := randIntn(edges.Len())
 	chosen[i] = true
 	selected = append(selected, edges.Slice()[i])
 
@@ -30,7 +32,7 @@ func GetMSCOrder(edges Edges) Edges {
 		maxcard := 0
 
 		for current := range edges.Slice() {
-			currentCard := edges.Slice()[current].numNeighboursOrder(edges, chosen)
+			currentCard := numNeighboursOrder(sets[current], edges, chosen)
 			if !chosen[current] && currentCard >= maxcard {
 				if currentCard > maxcard {
 					candidates = []int{}
@@ -42,7 +44,7 @@ func GetMSCOrder(edges Edges) Edges {
 		}
 
 		//randomly select one of the edges with equal connectivity
-		nextInOrder := candidates[rand.Intn(len(candidates))]
+		nextInOrder := candidates[randIntn(len(candidates))]
 
 		selected = append(selected, edges.Slice()[nextInOrder])
 		chosen[nextInOrder] = true
@@ -51,121 +53,203 @@ func GetMSCOrder(edges Edges) Edges {
 	return NewEdges(selected)
 }
 
-// GetMaxSepOrder orders the edges by how much they increase shortest paths within the hypergraph, using basic Floyd-Warschall (using the primal graph)
-func GetMaxSepOrder(edges Edges) Edges {
-	if edges.Len() <= 1 {
-		return edges
-	}
-	vertices := edges.Vertices()
-	weights := make([]int, edges.Len())
+// maxSepSources bounds the number of vertices from which distances are computed in GetMaxSepOrder. On graphs with
+// more vertices, a random sample of that size is used instead.
+const maxSepSources = 250
 
-	initialDiff, order := getMinDistances(vertices, edges)
+// unreachable marks vertices not reachable via BFS
+const unreachable = -1
 
-	for i, e := range edges.Slice() {
-		edgesWihoutE := diffEdges(edges, e)
-		newDiff, _ := getMinDistances(vertices, edgesWihoutE)
-		newDiffPrep := addEdgeDistances(order, newDiff, e)
-		weights[i] = diffDistances(initialDiff, newDiffPrep)
-	}
+// maxSepPrimal is the primal graph used by GetMaxSepOrder, with vertices mapped to 0 ... n-1
+type maxSepPrimal struct {
+	adj   [][]int        // adjacency lists
+	mult  []map[int]int  // number of edges covering each pair of adjacent vertices
+	index map[int]int    // maps vertices to their position
+	dup   map[uint64]int // number of edges with the same vertices, which are all removed together
+	edges [][]int        // the vertices of each edge, by position
+}
 
-	sort.Slice(edges.Slice(), func(i, j int) bool { return weights[i] > weights[j] })
+func getMaxSepPrimal(edges Edges) maxSepPrimal {
+	var p maxSepPrimal
 
-	return edges
-}
+	vertices := edges.Vertices()
+	p.index = make(map[int]int, len(vertices))
+	for i, v := range vertices {
+		p.index[v] = i
+	}
 
-func order(a, b int) (int, int) {
-	if a < b {
-		return a, b
+	p.adj = make([][]int, len(vertices))
+	p.mult = make([]map[int]int, len(vertices))
+	for i := range p.mult {
+		p.mult[i] = make(map[int]int)
 	}
-	return b, a
-}
+	p.dup = make(map[uint64]int)
 
-func isInf(a int) bool {
-	return a == math.MaxInt64
-}
+	for _, e := range edges.Slice() {
+		p.dup[e.Hash()]++
+
+		var positions []int
+		seen := make(map[int]bool, len(e.Vertices))
+		for _, v := range e.Vertices {
+			if !seen[p.index[v]] {
+				seen[p.index[v]] = true
+				positions = append(positions, p.index[v])
+			}
+		}
+		p.edges = append(p.edges, positions)
 
-func addEdgeDistances(order map[int]int, output [][]int, e Edge) [][]int {
-	for _, n := range e.Vertices {
-		for _, m := range e.Vertices {
-			nIndex, _ := order[n]
-			mIndex, _ := order[m]
-			if nIndex != mIndex {
-				output[nIndex][mIndex] = 1
+		for _, x := range positions {
+			for _, y := range positions {
+				if x == y {
+					continue
+				}
+				if p.mult[x][y] == 0 {
+					p.adj[x] = append(p.adj[x], y)
+				}
+				p.mult[x][y]++
 			}
 		}
 	}
 
-	return output
+	return p
 }
 
-func getMinDistances(vertices []int, edges Edges) ([][]int, map[int]int) {
-	var output [][]int
-	order := make(map[int]int)
-
-	for i, n := range vertices {
-		order[n] = i
+// bfs computes the distances from source, skipping the pairs of vertices marked as removed
+func (p maxSepPrimal) bfs(source int, dist []int, queue []int, removed func(x, y int) bool) {
+	for i := range dist {
+		dist[i] = unreachable
 	}
-
-	row := make([]int, len(vertices))
-	for j := 0; j < len(vertices); j++ {
-		row[j] = math.MaxInt64
+	dist[source] = 0
+	queue = append(queue[:0], source)
+
+	for len(queue) > 0 {
+		x := queue[0]
+		queue = queue[1:]
+		for _, y := range p.adj[x] {
+			if dist[y] != unreachable || (removed != nil && removed(x, y)) {
+				continue
+			}
+			dist[y] = dist[x] + 1
+			queue = append(queue, y)
+		}
 	}
+}
 
-	for j := 0; j < len(vertices); j++ {
-		newRow := make([]int, len(vertices))
-		copy(newRow, row)
-		output = append(output, newRow)
+// maxSepWeight computes how much removing the edge shortens paths between the sources and all other vertices, which
+// is never positive. Paths disconnected by the removal, as well as paths within the edge itself, are not counted.
+func (p maxSepPrimal) maxSepWeight(edge int, hash uint64, sources []int, dists [][]int, dist []int, queue []int,
+	inEdge []bool) int {
+	for _, x := range p.edges[edge] {
+		inEdge[x] = true
 	}
-
-	for _, e := range edges.Slice() {
-		output = addEdgeDistances(order, output, e)
+	defer func() {
+		for _, x := range p.edges[edge] {
+			inEdge[x] = false
+		}
+	}()
+
+	// the pairs only covered by the removed edge (and any duplicates of it)
+	var private [][2]int
+	for _, x := range p.edges[edge] {
+		for _, y := range p.edges[edge] {
+			if x < y && p.mult[x][y] == p.dup[hash] {
+				private = append(private, [2]int{x, y})
+			}
+		}
+	}
+	if len(private) == 0 {
+		return 0
+	}
+	removed := func(x, y int) bool {
+		return inEdge[x] && inEdge[y] && p.mult[x][y] == p.dup[hash]
 	}
 
-	for j := 0; j < edges.Len(); j++ {
-		changed := false
-		for k := range vertices {
-			for l := range vertices {
-				if isInf(output[k][l]) {
-					continue
-				}
-				for m := range vertices {
-					if isInf(output[l][m]) {
-						continue
-					}
-					newdist := output[k][l] + output[l][m]
-					if output[k][m] > newdist {
-						output[k][m] = newdist
-						changed = true
-					}
-				}
+	output := 0
+	for i, s := range sources {
+		old := dists[i]
+
+		// distances from s only change if a removed pair lies on a shortest path from it
+		tight := false
+		for _, pair := range private {
+			x, y := old[pair[0]], old[pair[1]]
+			if x != unreachable && y != unreachable && (x-y == 1 || y-x == 1) {
+				tight = true
+				break
 			}
 		}
-		if !changed {
-			break
+		if !tight {
+			continue
+		}
+
+		p.bfs(s, dist, queue, removed)
+		for v := range dist {
+			if v == s || dist[v] == unreachable || (inEdge[s] && inEdge[v]) {
+				continue
+			}
+			output = output - (dist[v] - old[v])
 		}
 	}
 
-	return output, order
+	return output
 }
 
-//  weight of each edge = (sum of path disconnected)*SepWeight  +  (sum of each path made longer * diff)
-func diffDistances(old, new [][]int) int {
-	var output int
+// GetMaxSepOrder orders the edges by how much they increase shortest paths within the hypergraph, using BFS on the
+// primal graph. Edges with equal weights keep their given order, and the given edges are not modified. On graphs with
+// more than maxSepSources vertices, only the paths starting from a random sample of vertices are considered, so the
+// order is only exact on smaller graphs.
+func GetMaxSepOrder(edges Edges) Edges {
+	if edges.Len() <= 1 {
+		return edges
+	}
+	p := getMaxSepPrimal(edges)
+	n := len(p.adj)
 
-	SepWeight := len(old) * len(old)
+	sources := make([]int, n)
+	for i := range sources {
+		sources[i] = i
+	}
+	if n > maxSepSources {
+		r := newRand()
+		r.Shuffle(n, func(i, j int) { sources[i], sources[j] = sources[j], sources[i] })
+		sources = sources[:maxSepSources]
+		sort.Ints(sources)
+	}
+
+	dists := make([][]int, len(sources))
+	for i, s := range sources {
+		dists[i] = make([]int, n)
+		p.bfs(s, dists[i], nil, nil)
+	}
 
-	for j := 0; j < len(old); j++ {
-		for i := 0; i < len(old[j]); i++ {
-			if isInf(old[j][i]) && !isInf(new[j][i]) { // disconnected a path
-				output = output + SepWeight
-			} else if !isInf(old[j][i]) && !isInf(new[j][i]) { // check if path shortened
-				diff := old[j][i] - new[j][i]
-				output = output + diff
+	weights := make([]int, edges.Len())
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(-1)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			dist := make([]int, n)
+			queue := make([]int, 0, n)
+			inEdge := make([]bool, n)
+			for i := w; i < edges.Len(); i = i + workers {
+				weights[i] = p.maxSepWeight(i, edges.Slice()[i].Hash(), sources, dists, dist, queue, inEdge)
 			}
-		}
+		}(w)
 	}
+	wg.Wait()
 
-	return output
+	keys := make([]int, edges.Len())
+	for i := range keys {
+		keys[i] = i
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return weights[keys[i]] > weights[keys[j]] })
+
+	output := make([]Edge, edges.Len())
+	for i, k := range keys {
+		output[i] = edges.Slice()[k]
+	}
+
+	return NewEdges(output)
 }
 
 // GetDegreeOrder orders the edges based on the sum of the vertex degrees
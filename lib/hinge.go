@@ -5,10 +5,11 @@ package lib
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"reflect"
-
-	"github.com/cem-okulmus/disjoint"
+	"runtime"
+	"time"
 )
 
 type hingeEdge struct {
@@ -34,6 +35,15 @@ type Hingetree struct {
 	children []hingeEdge
 }
 
+// Len returns the number of hinges in the hingetree
+func (h Hingetree) Len() int {
+	output := 1
+	for _, c := range h.children {
+		output = output + c.h.Len()
+	}
+	return output
+}
+
 // GetLargestGraph returns the largest graph within the hinge tree
 func (h Hingetree) GetLargestGraph() Graph {
 	maxEdges := h.hinge.Edges.Len()
@@ -111,9 +121,9 @@ func (h Hingetree) expandHingeTree(isUsed map[int]bool, parentE int) Hingetree {
 			continue
 		}
 
-		var Vertices = make(map[int]*disjoint.Element)
+		var ws CompWorkspace
 		sepEdge := NewEdges([]Edge{*e})
-		hinges, gamma, _ := h.hinge.GetComponents(sepEdge, Vertices)
+		hinges, gamma, _ := h.hinge.GetComponents(sepEdge, &ws)
 
 		// Skip reordering step if only single component
 		if len(hinges) <= 1 {
@@ -218,21 +228,107 @@ func (n Node) RerootEdge(edge []int) Node {
 	return child
 }
 
+// A CopyableAlgorithm can create independent instances of itself, so that several graphs can be decomposed in
+// parallel. The search of an instance ends once cancel is closed, rejecting the graph.
+type CopyableAlgorithm interface {
+	AlgorithmH
+	Copy(cancel <-chan struct{}) AlgorithmH
+}
+
+// A HingeSelector chooses the algorithm used to decompose each hinge of a hingetree. Each hinge must get its own
+// instance, whose search ends once cancel is closed.
+type HingeSelector interface {
+	Select(hinge Graph, cancel <-chan struct{}) AlgorithmH
+}
+
+// fixedSelector uses a copy of the same algorithm for all graphs. Algorithms which can't be copied are shared, and
+// can't be cancelled.
+type fixedSelector struct {
+	alg AlgorithmH
+}
+
+func (f fixedSelector) Select(hinge Graph, cancel <-chan struct{}) AlgorithmH {
+	if c, ok := f.alg.(CopyableAlgorithm); ok {
+		return c.Copy(cancel)
+	}
+	return f.alg
+}
+
+// HingeStat records the outcome of decomposing a single hinge
+type HingeStat struct {
+	Edges     int           // number of edges of the hinge
+	Algorithm string        // name of the algorithm chosen for the hinge
+	Width     int           // width of the decomposition found for the hinge
+	Time      time.Duration // time spent decomposing the hinge
+	Solved    bool          // false if no decomposition was found, or the hinge was skipped after a rejection
+}
+
+func (s HingeStat) String() string {
+	if !s.Solved {
+		if s.Time == 0 {
+			return fmt.Sprintf("%v edges, %v, skipped", s.Edges, s.Algorithm)
+		}
+		return fmt.Sprintf("%v edges, %v, rejected after %.5f ms", s.Edges, s.Algorithm, s.Time.Seconds()*1000)
+	}
+	return fmt.Sprintf("%v edges, %v, width %v, %.5f ms", s.Edges, s.Algorithm, s.Width, s.Time.Seconds()*1000)
+}
+
 // DecompHinge computes a decomposition of the original input graph,
 // using the hingetree to speed up the computation
 func (h Hingetree) DecompHinge(alg AlgorithmH, g Graph) Decomp {
-	h.decomp = alg.FindDecompGraph(h.hinge)
+	decomp, _ := h.DecompHingeStats(alg, g)
+	return decomp
+}
 
-	if reflect.DeepEqual(h.decomp, Decomp{}) {
-		return Decomp{}
+// DecompHingeStats computes a decomposition of the original input graph, decomposing the hinges in parallel, with at
+// most GOMAXPROCS hinges at a time. Each hinge is decomposed by its own copy of the algorithm, or one after the other if
+// the algorithm can't be copied. As soon as any hinge is rejected, no further hinges are started, the hinges still
+// running are cancelled, and the decomposition is rejected once they have ended. Also returns the stats of each hinge,
+// in pre-order of the hingetree.
+func (h Hingetree) DecompHingeStats(alg AlgorithmH, g Graph) (Decomp, []HingeStat) {
+	var graphs []Graph
+	h.collectHinges(&graphs)
+
+	decomps, stats, ok := decompAllWith(alg, graphs)
+	return h.glue(decomps, stats, ok, g)
+}
+
+// DecompHingeSelect works like DecompHingeStats, using the algorithm chosen by the selector for each hinge
+func (h Hingetree) DecompHingeSelect(sel HingeSelector, g Graph) (Decomp, []HingeStat) {
+	var graphs []Graph
+	h.collectHinges(&graphs)
+
+	decomps, stats, ok := decompAll(sel, graphs, runtime.GOMAXPROCS(-1))
+	return h.glue(decomps, stats, ok, g)
+}
+
+// glue combines the decomps of the hinges, unless one of them was rejected
+func (h Hingetree) glue(decomps []Decomp, stats []HingeStat, ok bool, g Graph) (Decomp, []HingeStat) {
+	if !ok {
+		return Decomp{}, stats
 	}
 
+	next := 0
+	return h.glueHinges(decomps, &next, g), stats
+}
+
+// collectHinges lists the hinges of the hingetree in pre-order
+func (h Hingetree) collectHinges(graphs *[]Graph) {
+	*graphs = append(*graphs, h.hinge)
+	for i := range h.children {
+		h.children[i].h.collectHinges(graphs)
+	}
+}
+
+// glueHinges combines the decomps of all hinges, given in pre-order, into a decomp of the original graph
+func (h Hingetree) glueHinges(decomps []Decomp, next *int, g Graph) Decomp {
+	h.decomp = decomps[*next]
+	*next++
+
 	// go recursively over children
 	for i := range h.children {
-		out := h.children[i].h.DecompHinge(alg, g)
-		if reflect.DeepEqual(out, Decomp{}) { // reject if subtree cannot be merged to GHD
-			return Decomp{}
-		}
+		out := h.children[i].h.glueHinges(decomps, next, g)
+
 		//reroot child and parent to a connecting node:
 		out.Root = out.Root.RerootEdge(h.children[i].e.Vertices)
 		h.decomp.Root = h.decomp.Root.RerootEdge(h.children[i].e.Vertices)
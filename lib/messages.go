@@ -0,0 +1,18 @@
+package lib
+
+// messages.go holds the destination of the messages printed by the library
+
+import (
+	"io"
+	"os"
+)
+
+// messages receives the messages printed by the library, such as parse errors or the reasons why a decomposition is
+// not correct
+var messages io.Writer = os.Stdout
+
+// SetOutput sets the destination of the messages printed by the library, which is standard output by default. It must
+// not be called while any other function of the library is running.
+func SetOutput(w io.Writer) {
+	messages = w
+}
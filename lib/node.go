@@ -9,10 +9,12 @@ import (
 )
 
 // A Node is the root of a labelled tree, where the labels are the bag
-// and the (edge) cover
+// and the (edge) cover. Nodes built by NewNode also store their bag as a VertexSet, so the bag must not be changed
+// afterwards.
 type Node struct {
 	num        int
 	Bag        []int
+	bag        VertexSet // the bag as a set, empty unless built by NewNode
 	Cover      Edges
 	Cost       float64
 	Children   []Node
@@ -90,9 +92,39 @@ func (n Node) contains(o Node) bool {
 	return false
 }
 
+// NewNode is a constructor for Node, storing the bag both as a slice and as a set
+func NewNode(bag []int, cover Edges) Node {
+	return Node{Bag: bag, bag: NewVertexSet(bag), Cover: cover}
+}
+
+// setBag replaces the bag of the node, along with its set
+func (n *Node) setBag(bag []int) {
+	n.Bag = bag
+	n.bag = NewVertexSet(bag)
+}
+
+// BagSet returns the bag of the node as a VertexSet, which is only built here if the node was not made by NewNode
+func (n Node) BagSet() VertexSet {
+	if n.bag.words == nil && len(n.Bag) > 0 {
+		return NewVertexSet(n.Bag)
+	}
+	return n.bag
+}
+
+// bagSets collects the bags of all nodes in the subtree rooted at n
+func (n Node) bagSets(output []VertexSet) []VertexSet {
+	output = append(output, n.BagSet())
+
+	for i := range n.Children {
+		output = n.Children[i].bagSets(output)
+	}
+
+	return output
+}
+
 // bagSubsets checks if all bags are proper subsets of the union of their covers
 func (n Node) bagSubsets() bool {
-	if !Subset(n.Bag, n.Cover.Vertices()) {
+	if !n.BagSet().SubsetOf(n.Cover.VertexSet()) {
 		// log.Println("Bag:", PrintVertices(n.Bag), "Cover: ", n.Cover)
 		return false
 	}
@@ -106,16 +138,10 @@ func (n Node) bagSubsets() bool {
 	return true
 }
 
-// checks if an edge appears as a subset in some bag in the subtree of n
-func (n Node) coversEdge(e Edge) bool {
-	// edge contained in current node
-	if Subset(e.Vertices, n.Bag) {
-		return true
-	}
-
-	// Check recursively if contained in children
-	for i := range n.Children {
-		if n.Children[i].coversEdge(e) {
+// coveredBy checks if an edge appears as a subset in one of the given bags
+func (e Edge) coveredBy(bags []VertexSet) bool {
+	for i := range bags {
+		if e.Set().SubsetOf(bags[i]) {
 			return true
 		}
 	}
@@ -200,7 +226,18 @@ func (n Node) Reroot(child Node) Node {
 	p.Children = newparentchildren
 	newchildren := append(child.Children, p)
 
-	return Node{Bag: child.Bag, Cover: child.Cover, Children: newchildren}
+	return Node{Bag: child.Bag, bag: child.bag, Cover: child.Cover, Cost: child.Cost, Children: newchildren}
+}
+
+// totalCost recursively sums up the costs of this node and all its children
+func (n Node) totalCost() float64 {
+	output := n.Cost
+
+	for i := range n.Children {
+		output = output + n.Children[i].totalCost()
+	}
+
+	return output
 }
 
 // Vertices recursively collects all vertices from the bag of this node, and the bags of all its children
@@ -222,16 +259,14 @@ func (n *Node) Vertices() []int {
 
 // specialCondition tests special condition violation on one node
 func (n Node) specialCondition() bool {
-	hiddenVertices := Diff(n.Cover.Vertices(), n.Bag)
-	verticesRooted := n.Vertices()
-
-	for _, v := range hiddenVertices {
-		if mem(verticesRooted, v) {
-			mutex.RLock()
-			log.Println("Vertex ", m[v], " violates special condition")
-			mutex.RUnlock()
-			return false
-		}
+	hiddenVertices := n.Cover.VertexSet().Diff(n.BagSet())
+	violating := hiddenVertices.Inter(NewVertexSet(n.Vertices()))
+
+	if !violating.IsEmpty() {
+		mutex.RLock()
+		log.Println("Vertex ", m[violating.Slice()[0]], " violates special condition")
+		mutex.RUnlock()
+		return false
 	}
 
 	return true
@@ -263,7 +298,7 @@ OUTER:
 			continue
 		}
 		for _, e := range edges.Slice() {
-			if Subset(e2.Vertices, e.Vertices) && e.Name != 0 {
+			if e.Name != 0 && e2.Set().SubsetOf(e.Set()) {
 				nuCover = append(nuCover, e)
 				continue OUTER
 			}
@@ -276,7 +311,7 @@ OUTER:
 		nuChildern = append(nuChildern, n.Children[i].restoreEdges(edges))
 	}
 
-	return Node{Bag: n.Bag, Cover: NewEdges(nuCover), Cost: n.Cost, Children: nuChildern}
+	return Node{Bag: n.Bag, bag: n.bag, Cover: NewEdges(nuCover), Cost: n.Cost, Children: nuChildern}
 }
 
 // CombineNodes attaches subtree to n, via the connecting special edge
@@ -300,31 +335,24 @@ func (n *Node) CombineNodes(subtree Node, connecting Edges) *Node {
 	return nil
 }
 
-func (n Node) connected(v int, parentContainsV bool) (bool, bool) {
-	containsV := mem(n.Bag, v)
-	subtreeContainsV := containsV
-	numNeighboursContainingV := 0
-
-	if parentContainsV {
-		numNeighboursContainingV = 1
-	}
+// connected checks the connectedness condition for all vertices of the subtree rooted at n at once. A vertex
+// violates it at n if it is not in the bag of n, but occurs in the bag of the parent or the subtree of a child of n
+// more than once. All such vertices are added to violations, and the vertices of the subtree are returned.
+func (n Node) connected(parentBag VertexSet, violations *VertexSet) VertexSet {
+	bag := n.BagSet()
+	subtree := bag.Clone()
+	once := parentBag.Clone()
+	var twice VertexSet
 
 	for i := range n.Children {
-		connected, subtreeFlag := n.Children[i].connected(v, containsV)
+		child := n.Children[i].connected(bag, violations)
 
-		if !connected { // stop if subtree rooted at child i already has a disconnected subtree
-			return false, false
-		}
-
-		if subtreeFlag {
-			numNeighboursContainingV++
-			subtreeContainsV = true
-		}
+		twice.UnionWith(once.Inter(child))
+		once.UnionWith(child)
+		subtree.UnionWith(child)
 	}
 
-	if !containsV && numNeighboursContainingV > 1 {
-		return false, false
-	}
+	violations.UnionWith(twice.Diff(bag))
 
-	return true, subtreeContainsV
+	return subtree
 }
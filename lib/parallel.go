@@ -0,0 +1,74 @@
+package lib
+
+// parallel.go decomposes several graphs in parallel, as done for the hinges of a hingetree, the blocks of a graph
+// and the parts between safe separators
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// decompAll decomposes the graphs in parallel, with at most the given number of workers at a time, each graph with
+// its own instance of the algorithm chosen by the selector. As soon as any graph is rejected, no further graphs are
+// started and the ones still running are cancelled. Once all have ended, returns the decomps and the stats of each
+// graph, and false if any graph was rejected.
+func decompAll(sel HingeSelector, graphs []Graph, workers int) ([]Decomp, []HingeStat, bool) {
+	var once sync.Once
+	failed := make(chan struct{}) // closed on the first rejection, cancelling all graphs
+
+	algs := make([]AlgorithmH, len(graphs))
+	decomps := make([]Decomp, len(graphs))
+	stats := make([]HingeStat, len(graphs))
+	for i := range graphs {
+		algs[i] = sel.Select(graphs[i], failed)
+		stats[i].Edges = graphs[i].Edges.Len()
+		stats[i].Algorithm = algs[i].Name()
+	}
+
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(len(graphs))
+	for i := range graphs {
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-failed:
+				return
+			}
+			defer func() { <-sem }()
+			if Cancelled(failed) {
+				return
+			}
+
+			start := time.Now()
+			decomp := DecompGraph(algs[i], graphs[i])
+
+			decomps[i] = decomp
+			stats[i].Time = time.Now().Sub(start)
+			if reflect.DeepEqual(decomp, Decomp{}) {
+				once.Do(func() { close(failed) })
+				return
+			}
+			stats[i].Solved = true
+			stats[i].Width = decomp.CheckWidth()
+		}(i)
+	}
+	wg.Wait()
+
+	return decomps, stats, !Cancelled(failed)
+}
+
+// decompAllWith decomposes the graphs like decompAll, using copies of the given algorithm. If it can't be copied,
+// the graphs are decomposed one after the other, as the algorithm must not be used by several goroutines at once.
+func decompAllWith(alg AlgorithmH, graphs []Graph) ([]Decomp, []HingeStat, bool) {
+	workers := 1
+	if _, ok := alg.(CopyableAlgorithm); ok {
+		workers = runtime.GOMAXPROCS(-1)
+	}
+
+	return decompAll(fixedSelector{alg: alg}, graphs, workers)
+}
@@ -59,14 +59,22 @@ func GetGraph(s string) (Graph, ParseGraph) {
 
 	var parser = participle.MustBuild(&ParseGraph{}, participle.UseLookahead(1), participle.Lexer(graphLexer),
 		participle.Elide("Comment", "Whitespace"))
-	var output Graph
-	var edges []Edge
 	pgraph := ParseGraph{}
 	err := parser.ParseString(s, &pgraph)
 	if err != nil {
-		fmt.Println("Couldn't parse input: ")
+		fmt.Fprintln(messages, "Couldn't parse input: ")
 		panic(err)
 	}
+
+	return pgraph.encodeGraph(), pgraph
+}
+
+// encodeGraph fixes the integer encoding of all vertices and edge names of a parsed graph, and produces the
+// corresponding graph structure
+func (pgraph *ParseGraph) encodeGraph() Graph {
+	var output Graph
+	var edges []Edge
+
 	encoding := make(map[int]string)
 	encode = 1 // initialize to 1
 	pgraph.Encoding = make(map[string]int)
@@ -105,14 +113,14 @@ func GetGraph(s string) (Graph, ParseGraph) {
 			outputEdges = append(outputEdges, i)
 
 		}
-		edges = append(edges, Edge{Name: pgraph.Encoding[e.Name], Vertices: outputEdges})
+		edges = append(edges, NewEdge(pgraph.Encoding[e.Name], outputEdges))
 	}
 
 	encode = encode + len(pgraph.Edges)
 
 	output.Edges = NewEdges(edges)
 	m = encoding
-	return output, pgraph
+	return output
 }
 
 // GetEdge can be used parse additional hyperedges. Useful for testing purposes
@@ -146,7 +154,7 @@ func (p *ParseGraph) GetEdge(input string) Edge {
 	}
 	m[encode] = pEdge.Name
 	encode++
-	return Edge{Vertices: vertices, Name: encode - 1}
+	return NewEdge(encode-1, vertices)
 }
 
 // Implement PACE 2019 format
@@ -188,7 +196,7 @@ func GetGraphPACE(s string) Graph {
 	pgraph := parseGraphPACE{}
 	err := parser.ParseString(s, &pgraph)
 	if err != nil {
-		fmt.Println("Couldn't parse input: ")
+		fmt.Fprintln(messages, "Couldn't parse input: ")
 		panic(err)
 	}
 	encode = 1 // initialize to 1
@@ -215,7 +223,7 @@ func GetGraphPACE(s string) Graph {
 				encode++
 			}
 		}
-		edges = append(edges, Edge{Name: pgraph.m[e.Name], Vertices: outputEdges})
+		edges = append(edges, NewEdge(pgraph.m[e.Name], outputEdges))
 	}
 
 	m = encoding
@@ -306,9 +314,11 @@ func (n NodeJson) IntoNode(graph Graph, encoding map[string]int) Node {
 	var output Node
 	var cover []Edge
 
+	var bag []int
 	for i := range n.Bag {
-		output.Bag = append(output.Bag, encoding[n.Bag[i]])
+		bag = append(bag, encoding[n.Bag[i]])
 	}
+	output.setBag(bag)
 
 	for i := range n.Cover {
 		cover = append(cover, extractEdge(graph.Edges.Slice(), encoding[n.Cover[i]]))
@@ -328,7 +338,7 @@ func GetDecomp(input []byte, graph Graph, encoding map[string]int) Decomp {
 
 	err := json.Unmarshal(input, &jason)
 	if err != nil {
-		fmt.Println("error:", err)
+		fmt.Fprintln(messages, "error:", err)
 		log.Panicln("decomp couldn't be parased")
 	}
 
@@ -339,7 +349,7 @@ func WriteDecomp(input Decomp) []byte {
 	out, err := json.Marshal(input.IntoJson())
 
 	if err != nil {
-		fmt.Println("error:", err)
+		fmt.Fprintln(messages, "error:", err)
 		log.Panicln("decomp couldn't be marshalled")
 	}
 
@@ -387,7 +397,7 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 	pDecomp := parseGML{}
 	err := parser.ParseString(input, &pDecomp)
 	if err != nil {
-		fmt.Println("Couldn't parse input: ")
+		fmt.Fprintln(messages, "Couldn't parse input: ")
 		panic(err)
 	}
 
@@ -458,7 +468,7 @@ func GetDecompGML(input string, graph Graph, encoding map[string]int) Decomp {
 
 			encode = max(node.num, encode) + 1 // ensure encode will never collide with num values in parsed GMl
 
-			node.Bag = bag
+			node.setBag(bag)
 			node.Cover = NewEdges(cover)
 
 			nodes = append(nodes, node)
@@ -6,7 +6,8 @@ package lib
 
 import (
 	"fmt"
-	"math/big"
+	"hash/fnv"
+	"sort"
 )
 
 // A GYÖReduct (that's short for GYÖ (Graham - Yu - Özsoyoğlu) Reduction )
@@ -43,76 +44,245 @@ func (v vertOp) String() string {
 	return fmt.Sprintf("(%v ∈ %v)", m[v.vertex], v.edge)
 }
 
-// Performs one part of GYÖ reduct
-func (g Graph) removeEdges() (Graph, []GYÖReduct) {
-	var output []Edge
-	var removed []Edge
-	var ops []GYÖReduct
+// gyöState keeps track of the edges during the GYÖ reduction. Edges keep their position throughout, and an index
+// from vertices to the positions of edges containing them replaces the scans over all edges.
+type gyöState struct {
+	edges    []Edge
+	alive    []bool
+	incident map[int][]int // positions of the edges containing each vertex, in ascending order
+	degree   map[int]int   // the number of alive edges containing each vertex
+	mark     map[int]int   // used to check subsets without allocations
+	epoch    int
+}
 
-OUTER:
-	for _, e1 := range g.Edges.Slice() {
-		for _, e2 := range g.Edges.Slice() {
-			if e1.Name != e2.Name && !e2.containedIn(removed) && Subset(e1.Vertices, e2.Vertices) {
-				ops = append(ops, edgeOp{subedge: e1, parent: e2})
-				removed = append(removed, e1)
-				continue OUTER
+func newGyöState(edges []Edge) *gyöState {
+	s := gyöState{
+		edges:    make([]Edge, len(edges)),
+		alive:    make([]bool, len(edges)),
+		incident: make(map[int][]int),
+		degree:   make(map[int]int),
+		mark:     make(map[int]int),
+	}
+	copy(s.edges, edges)
+
+	for i, e := range edges {
+		s.alive[i] = true
+		for _, v := range e.Vertices {
+			if inc := s.incident[v]; len(inc) == 0 || inc[len(inc)-1] != i { // count each edge once
+				s.incident[v] = append(inc, i)
+				s.degree[v]++
 			}
 		}
+	}
+
+	return &s
+}
+
+// subset checks if the vertices of the edge at position i are contained in those of the edge at position j
+func (s *gyöState) subset(i, j int) bool {
+	s.epoch++
+	for _, v := range s.edges[j].Vertices {
+		s.mark[v] = s.epoch
+	}
+	for _, v := range s.edges[i].Vertices {
+		if s.mark[v] != s.epoch {
+			return false
+		}
+	}
+	return true
+}
+
+// parent finds the first edge, in the order of the edges, which contains the edge at position i and which is still
+// available as a parent
+func (s *gyöState) parent(i int, removedNames map[int]bool) int {
+	valid := func(j int) bool {
+		return s.alive[j] && s.edges[j].Name != s.edges[i].Name && !removedNames[s.edges[j].Name]
+	}
+
+	if len(s.edges[i].Vertices) == 0 {
+		for j := range s.edges {
+			if valid(j) {
+				return j
+			}
+		}
+		return -1
+	}
+
+	// any parent must contain the vertex of least degree
+	rarest := s.edges[i].Vertices[0]
+	for _, v := range s.edges[i].Vertices {
+		if s.degree[v] < s.degree[rarest] {
+			rarest = v
+		}
+	}
 
-		output = append(output, e1)
+	for _, j := range s.incident[rarest] {
+		if valid(j) && s.subset(i, j) {
+			return j
+		}
 	}
+	return -1
+}
+
+// removeEdges removes, in the order of the edges, each candidate edge contained in some other edge. It returns the
+// vertices whose degree decreased.
+func (s *gyöState) removeEdges(candidates []int) ([]GYÖReduct, []int) {
+	var ops []GYÖReduct
+	var changed []int
+	removedNames := make(map[int]bool)
 
-	return Graph{Edges: NewEdges(output)}, ops
+	for _, i := range candidates {
+		if !s.alive[i] {
+			continue
+		}
+		j := s.parent(i, removedNames)
+		if j == -1 {
+			continue
+		}
+
+		ops = append(ops, edgeOp{subedge: s.edges[i], parent: s.edges[j]})
+		s.alive[i] = false
+		removedNames[s.edges[i].Name] = true
+
+		s.epoch++
+		for _, v := range s.edges[i].Vertices {
+			if s.mark[v] != s.epoch { // count each vertex once
+				s.mark[v] = s.epoch
+				s.degree[v]--
+				changed = append(changed, v)
+			}
+		}
+	}
+
+	return ops, changed
 }
 
-func (g Graph) removeVertices() (Graph, []GYÖReduct) {
+// removeVertices removes the candidate vertices contained in only one edge, in the order of the edges. It returns
+// the positions of the edges that lost vertices and remain in the graph.
+func (s *gyöState) removeVertices(candidates []int) ([]GYÖReduct, []int) {
 	var ops []GYÖReduct
-	var edges []Edge
+	var changed []int
+
+	var affected []int
+	seen := make(map[int]bool)
+	for _, v := range candidates {
+		if s.degree[v] != 1 {
+			continue
+		}
+		for _, i := range s.incident[v] {
+			if s.alive[i] && !seen[i] {
+				seen[i] = true
+				affected = append(affected, i)
+			}
+		}
+	}
+	sort.Ints(affected)
 
-	for _, e1 := range g.Edges.Slice() {
+	for _, i := range affected {
 		var vertices []int
 		var remVertices []int
-	INNER:
-		for _, v := range e1.Vertices {
-			if getDegree(g.Edges, v) == 1 {
+		for _, v := range s.edges[i].Vertices {
+			if s.degree[v] == 1 {
 				remVertices = append(remVertices, v)
-				continue INNER
+				continue
 			}
 			vertices = append(vertices, v)
 		}
-		nuE1 := Edge{Name: e1.Name, Vertices: vertices}
+		if len(remVertices) == 0 {
+			continue
+		}
+		nuE := NewEdge(s.edges[i].Name, vertices)
 
 		for _, remV := range remVertices {
-			ops = append(ops, vertOp{vertex: remV, edge: nuE1})
+			ops = append(ops, vertOp{vertex: remV, edge: nuE})
+		}
+		for _, remV := range remVertices {
+			delete(s.degree, remV)
 		}
 
+		s.edges[i] = nuE
 		if len(vertices) > 0 {
-			edges = append(edges, nuE1)
+			changed = append(changed, i)
+		} else {
+			s.alive[i] = false
 		}
+	}
 
+	return ops, changed
+}
+
+// remaining returns the edges left in the graph
+func (s *gyöState) remaining() Graph {
+	var output []Edge
+	for i := range s.edges {
+		if s.alive[i] {
+			output = append(output, s.edges[i])
+		}
 	}
 
-	return Graph{Edges: NewEdges(edges)}, ops
+	return Graph{Edges: NewEdges(output)}
 }
 
-// GYÖReduct performs the GYÖ reduction on the graph
+// GYÖReduct performs the GYÖ reduction on the graph. After the first round, which considers all edges and vertices,
+// only edges which lost vertices and vertices which lost edges in the previous step are considered again.
 func (g Graph) GYÖReduct() (Graph, []GYÖReduct) {
 	var ops []GYÖReduct
+	s := newGyöState(g.Edges.Slice())
 
-	for {
+	// an edge may only be contained in another with the same name once that name is no longer removed in the same
+	// round, so with repeated names all edges stay candidates
+	uniqueNames := true
+	names := make(map[int]bool)
+	for _, e := range g.Edges.Slice() {
+		uniqueNames = uniqueNames && !names[e.Name]
+		names[e.Name] = true
+	}
+
+	edgeCandidates := make([]int, len(s.edges))
+	for i := range edgeCandidates {
+		edgeCandidates[i] = i
+	}
+
+	for first := true; ; first = false {
 		//Perform edge removal
-		g1, ops1 := g.removeEdges()
+		ops1, vertexCandidates := s.removeEdges(edgeCandidates)
 		ops = append(ops, ops1...)
 
+		if first {
+			vertexCandidates = g.Edges.Vertices()
+		}
+
 		//Perform vertex removal
-		g2, ops2 := g1.removeVertices()
+		ops2, changed := s.removeVertices(vertexCandidates)
 		ops = append(ops, ops2...)
 
 		//Check if something changed
 		if len(ops2)+len(ops1) == 0 {
+			if first {
+				return g, ops
+			}
 			break
 		}
-		g = g2
+
+		if first {
+			// edges without any vertices are dropped, as they have no vertices to remove
+			for i := range s.edges {
+				if len(s.edges[i].Vertices) == 0 {
+					s.alive[i] = false
+				}
+			}
+		}
+
+		if uniqueNames {
+			edgeCandidates = changed
+		} else {
+			edgeCandidates = edgeCandidates[:0]
+			for i := range s.edges {
+				if s.alive[i] {
+					edgeCandidates = append(edgeCandidates, i)
+				}
+			}
+		}
 	}
 
 	//reverse order of ops
@@ -120,12 +290,18 @@ func (g Graph) GYÖReduct() (Graph, []GYÖReduct) {
 		ops[i], ops[j] = ops[j], ops[i]
 	}
 
-	return g, ops
+	return s.remaining(), ops
+}
+
+// IsAlphaAcyclic checks if the graph is α-acyclic, i.e. if the GYÖ reduction removes all of its edges
+func (g Graph) IsAlphaAcyclic() bool {
+	reduced, _ := g.GYÖReduct()
+	return reduced.Edges.Len() == 0
 }
 
 func (n Node) restoreEdgeOp(e edgeOp) (Node, bool) {
 	if Subset(e.parent.Vertices, n.Bag) {
-		n.Children = append(n.Children, Node{Bag: e.subedge.Vertices, Cover: NewEdges([]Edge{e.subedge})})
+		n.Children = append(n.Children, NewNode(e.subedge.Vertices, NewEdges([]Edge{e.subedge})))
 		return n, true // Won't work without deep copy
 	}
 
@@ -156,11 +332,11 @@ func (n Node) edgeUsed(e Edge) bool {
 }
 
 func (n Node) addLeaf(v vertOp) (Node, bool) {
-	edge := Edge{Name: v.edge.Name, Vertices: append(v.edge.Vertices, v.vertex)}
+	edge := NewEdge(v.edge.Name, append(v.edge.Vertices, v.vertex))
 
 	if Subset(v.edge.Vertices, n.Bag) {
 		nuCover := NewEdges([]Edge{edge})
-		n.Children = append(n.Children, Node{Bag: edge.Vertices, Cover: nuCover})
+		n.Children = append(n.Children, NewNode(edge.Vertices, nuCover))
 		return n, true // Won't work without deep copy
 	}
 
@@ -177,8 +353,8 @@ func (n Node) addLeaf(v vertOp) (Node, bool) {
 
 func (n Node) restoreVertex(v vertOp) (Node, bool) {
 	if len(n.Bag) == 0 && n.Cover.Len() == 0 && len(n.Children) == 0 {
-		edge := Edge{Name: v.edge.Name, Vertices: []int{v.vertex}}
-		return Node{Bag: []int{v.vertex}, Cover: NewEdges([]Edge{edge})}, true
+		edge := NewEdge(v.edge.Name, []int{v.vertex})
+		return NewNode([]int{v.vertex}, NewEdges([]Edge{edge})), true
 	}
 
 	if v.edge.containedIn(n.Cover.Slice()) && Subset(v.edge.Vertices, n.Bag) {
@@ -187,14 +363,17 @@ func (n Node) restoreVertex(v vertOp) (Node, bool) {
 
 		for _, e := range n.Cover.Slice() {
 			if e.Name == v.edge.Name {
-				edge := Edge{Name: e.Name, Vertices: append(e.Vertices, v.vertex)}
+				edge := NewEdge(e.Name, append(e.Vertices, v.vertex))
 				nuCover = append(nuCover, edge)
 			} else {
 				nuCover = append(nuCover, e)
 			}
 		}
 
-		return Node{Bag: append(n.Bag, v.vertex), Cover: NewEdges(nuCover), Children: n.Children}, true
+		output := NewNode(append(n.Bag, v.vertex), NewEdges(nuCover))
+		output.Cost = n.Cost
+		output.Children = n.Children
+		return output, true
 	}
 
 	for i := range n.Children {
@@ -226,7 +405,7 @@ func (n Node) RestoreGYÖ(reducts []GYÖReduct) (Node, bool) {
 			output, result = output.restoreEdgeOp(v)
 		}
 		if !result {
-			fmt.Println("Failed at restoring ", r)
+			fmt.Fprintln(messages, "Failed at restoring ", r)
 			return output, false
 		}
 	}
@@ -238,21 +417,51 @@ func (n Node) RestoreGYÖ(reducts []GYÖReduct) (Node, bool) {
 Type Collapse
 */
 
-func (g Graph) getType(vertex int) *big.Int {
-	output := new(big.Int)
+// getTypes computes the type of each vertex, i.e. the positions of the edges containing it, in ascending order
+func (g Graph) getTypes() map[int][]int {
+	output := make(map[int][]int)
 
-	for i := range g.Edges.Slice() {
-		if mem(g.Edges.Slice()[i].Vertices, vertex) {
-			output.SetBit(output, i, 1)
+	for i, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if t := output[v]; len(t) == 0 || t[len(t)-1] != i { // skip duplicates within an edge
+				output[v] = append(t, i)
+			}
 		}
 	}
+
 	return output
 }
 
+// hashType hashes the type of a vertex
+func hashType(t []int) uint64 {
+	h := fnv.New64a()
+	bs := make([]byte, 8)
+
+	for _, i := range t {
+		for j := range bs {
+			bs[j] = byte(i >> (8 * uint(j)))
+		}
+		h.Write(bs)
+	}
+
+	return h.Sum64()
+}
+
+func sameType(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // TypeCollapse performs type collapse on the graph, the mapping that's also output can be used
-// to restore the original hypergraph.
-//
-// Possible optimization: When computing the distances, use the matrix to speed up type detection
+// to restore the original hypergraph. Vertices of the same type are found by hashing their types, the first
+// vertex of each type is kept.
 func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 	count := 0
 
@@ -260,19 +469,28 @@ func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 	restorationMap := make(map[int][]int) // used to restore "full" edges from simplified one
 
 	// identify vertices to replace
-	encountered := make(map[string]int)
+	types := g.getTypes()
+	encountered := make(map[uint64][]int) // the representatives of each type, by hash
 
 	for _, v := range g.Vertices() {
-		typeString := g.getType(v).String()
+		hash := hashType(types[v])
+
+		rep, ok := -1, false
+		for _, w := range encountered[hash] {
+			if sameType(types[v], types[w]) {
+				rep, ok = w, true
+				break
+			}
+		}
 
-		if _, ok := encountered[typeString]; ok {
+		if ok {
 			// already seen this type before
 			count++
-			substituteMap[v] = encountered[typeString]
-			restorationMap[encountered[typeString]] = append(restorationMap[encountered[typeString]], v)
+			substituteMap[v] = rep
+			restorationMap[rep] = append(restorationMap[rep], v)
 		} else {
 			// Record this type as a new element
-			encountered[typeString] = v
+			encountered[hash] = append(encountered[hash], v)
 			substituteMap[v] = v
 		}
 	}
@@ -284,7 +502,7 @@ func (g Graph) TypeCollapse() (Graph, map[int][]int, int) {
 		for _, v := range e.Vertices {
 			vertices = append(vertices, substituteMap[v])
 		}
-		newEdges = append(newEdges, Edge{Name: e.Name, Vertices: RemoveDuplicates(vertices)})
+		newEdges = append(newEdges, NewEdge(e.Name, RemoveDuplicates(vertices)))
 	}
 
 	return Graph{Edges: NewEdges(newEdges)}, restorationMap, count
@@ -298,7 +516,7 @@ func (e Edges) addVertex(target int, oldVertices []int) Edges {
 			newLambda := make([]int, len(edges[i].Vertices))
 			copy(newLambda, edges[i].Vertices)
 			newLambda = append(newLambda, oldVertices...)
-			edges[i].Vertices = newLambda
+			edges[i] = NewEdge(edges[i].Name, newLambda)
 		}
 	}
 
@@ -308,7 +526,7 @@ func (e Edges) addVertex(target int, oldVertices []int) Edges {
 func (n Node) addVertices(target int, oldVertices []int) (Node, bool) {
 	found := false
 	if mem(n.Bag, target) {
-		n.Bag = append(n.Bag, oldVertices...)
+		n.setBag(append(n.Bag, oldVertices...))
 		n.Cover = n.Cover.addVertex(target, oldVertices)
 		found = true
 	}
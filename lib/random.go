@@ -0,0 +1,50 @@
+package lib
+
+// random.go provides the source of randomness for all randomized heuristics, so that runs can be reproduced by
+// setting a seed
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	randomMux sync.Mutex
+	seed      = time.Now().UnixNano()
+	random    = rand.New(rand.NewSource(seed))
+)
+
+// SetSeed resets the source of randomness with the given seed
+func SetSeed(s int64) {
+	randomMux.Lock()
+	defer randomMux.Unlock()
+
+	seed = s
+	random = rand.New(rand.NewSource(s))
+}
+
+// Seed returns the seed last used to set up the source of randomness
+func Seed() int64 {
+	randomMux.Lock()
+	defer randomMux.Unlock()
+
+	return seed
+}
+
+// randIntn returns a random number in [0,n)
+func randIntn(n int) int {
+	randomMux.Lock()
+	defer randomMux.Unlock()
+
+	return random.Intn(n)
+}
+
+// newRand returns a new source of randomness, seeded from the shared one. Useful for heuristics that need many
+// random numbers without locking.
+func newRand() *rand.Rand {
+	randomMux.Lock()
+	defer randomMux.Unlock()
+
+	return rand.New(rand.NewSource(random.Int63()))
+}
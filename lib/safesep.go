@@ -0,0 +1,345 @@
+package lib
+
+// safesep.go implements the splitting of a hypergraph at safe separators, such that each resulting part can be
+// decomposed independently
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A SafeSepReduct records the split of a part at a separator, whose vertices form a clique in the primal graph. Each
+// resulting part contains the separator as a special edge. The first part in Split reuses the index of the part that
+// was split.
+type SafeSepReduct struct {
+	Part  int
+	Sep   []int
+	Split []int
+}
+
+func (s SafeSepReduct) String() string {
+	return fmt.Sprintf("(%v split at %v into %v)", s.Part, PrintVertices(s.Sep), s.Split)
+}
+
+// SafeSeps stores the parts of a graph split at safe separators, and the list of splits that produced them
+type SafeSeps struct {
+	Parts []Graph
+	Ops   []SafeSepReduct
+	seps  [][]int // for each part, the splits whose separators it contains as special edges
+}
+
+// Len returns the number of parts
+func (s SafeSeps) Len() int {
+	return len(s.Parts)
+}
+
+// SplitSafeSeps splits a graph at clique minimal separators of its primal graph, computing the atoms via a minimal
+// triangulation produced by MCS-M (Berry et al. '10). The splits do not depend on the width: every clique is contained
+// in some bag of any decomposition, so the graph has a decomposition of width ≤ k if and only if every separator is
+// covered by at most k edges, and every part, extended by its separators as special edges, has one.
+// Almost-clique separators, which are safe for treewidth, are not considered, as their safety for generalized
+// hypertree width is not established.
+func SplitSafeSeps(g Graph) SafeSeps {
+	all := append([]Edge{}, g.Edges.Slice()...)
+	for _, sp := range g.Special {
+		all = append(all, NewEdge(0, sp.Vertices()))
+	}
+	adj := primal(NewEdges(all))
+	order, madj := mcsm(adj, nil)
+
+	output := SafeSeps{Parts: []Graph{g}, seps: [][]int{{}}}
+	removed := make(map[int]bool)
+	remaining := len(adj)
+
+	for i, x := range order {
+		// x generates a minimal separator of the triangulation if its weight did not increase compared to the vertex
+		// numbered before it
+		if i == len(order)-1 || len(madj[x]) > len(madj[order[i+1]]) || removed[x] {
+			continue
+		}
+		sep := madj[x]
+		if len(sep) == 0 || !isClique(sep, adj) || anyRemoved(sep, removed) {
+			continue
+		}
+
+		comp := separated(adj, x, sep, removed)
+		if len(comp)+len(sep) == remaining {
+			continue
+		}
+
+		atom, rest := output.splitPart(comp)
+		if atom.Edges.Len() == 0 || rest.Edges.Len() == 0 {
+			continue
+		}
+
+		op := SafeSepReduct{Part: 0, Sep: append([]int{}, sep...), Split: []int{0, len(output.Parts)}}
+		atomSeps, restSeps := output.splitSeps(comp)
+		output.Parts[0] = rest
+		output.seps[0] = append(restSeps, len(output.Ops))
+		output.Parts = append(output.Parts, atom)
+		output.seps = append(output.seps, append(atomSeps, len(output.Ops)))
+		output.Ops = append(output.Ops, op)
+
+		for v := range comp {
+			removed[v] = true
+		}
+		remaining = remaining - len(comp)
+	}
+
+	return output
+}
+
+// anyRemoved checks if any of the vertices has been removed
+func anyRemoved(vertices []int, removed map[int]bool) bool {
+	for _, v := range vertices {
+		if removed[v] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// separated returns the component of the remaining primal graph without sep that contains x
+func separated(adj map[int]map[int]struct{}, x int, sep []int, removed map[int]bool) map[int]struct{} {
+	blocked := make(map[int]struct{}, len(sep))
+	for _, v := range sep {
+		blocked[v] = Empty
+	}
+
+	output := map[int]struct{}{x: Empty}
+	stack := []int{x}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for w := range adj[v] {
+			_, isSep := blocked[w]
+			_, seen := output[w]
+			if isSep || seen || removed[w] {
+				continue
+			}
+			output[w] = Empty
+			stack = append(stack, w)
+		}
+	}
+
+	return output
+}
+
+// splitPart splits the edges and special edges of the first part, which is what remains of the graph, into those
+// touching the component comp and the others
+func (s SafeSeps) splitPart(comp map[int]struct{}) (Graph, Graph) {
+	touches := func(vertices []int) bool {
+		for _, v := range vertices {
+			if _, ok := comp[v]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var atomEdges, restEdges []Edge
+	for _, e := range s.Parts[0].Edges.Slice() {
+		if touches(e.Vertices) {
+			atomEdges = append(atomEdges, e)
+		} else {
+			restEdges = append(restEdges, e)
+		}
+	}
+
+	atom := Graph{Edges: NewEdges(atomEdges)}
+	rest := Graph{Edges: NewEdges(restEdges)}
+	for _, sp := range s.Parts[0].Special {
+		if touches(sp.Vertices()) {
+			atom.Special = append(atom.Special, sp)
+		} else {
+			rest.Special = append(rest.Special, sp)
+		}
+	}
+
+	return atom, rest
+}
+
+// splitSeps splits the separators contained in the first part into those touching the component comp and the others
+func (s SafeSeps) splitSeps(comp map[int]struct{}) ([]int, []int) {
+	var atomSeps, restSeps []int
+
+OUTER:
+	for _, i := range s.seps[0] {
+		for _, v := range s.Ops[i].Sep {
+			if _, ok := comp[v]; ok {
+				atomSeps = append(atomSeps, i)
+				continue OUTER
+			}
+		}
+		restSeps = append(restSeps, i)
+	}
+
+	return atomSeps, restSeps
+}
+
+// isClique checks if the given vertices are pairwise adjacent
+func isClique(vertices []int, adj map[int]map[int]struct{}) bool {
+	for i := range vertices {
+		for j := i + 1; j < len(vertices); j++ {
+			if _, ok := adj[vertices[i]][vertices[j]]; !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// coverSeparator looks for at most k edges covering the vertices of a separator, by branching on the edges
+// containing the first vertex not covered yet. Edges contained in the separator are tried first, followed by those
+// sharing the most vertices with it.
+func coverSeparator(sep []int, incident map[int][]Edge, k int) (Edges, bool) {
+	sepSet := NewVertexSet(sep)
+	shared := func(e Edge) (bool, int) {
+		output := 0
+		for _, v := range e.Vertices {
+			if sepSet.Has(v) {
+				output++
+			}
+		}
+		return output == len(e.Vertices), output
+	}
+
+	covered := make(map[int]int, len(sep))
+	var cover []Edge
+
+	var search func() bool
+	search = func() bool {
+		next := -1
+		for _, v := range sep {
+			if covered[v] == 0 {
+				next = v
+				break
+			}
+		}
+		if next == -1 {
+			return true
+		}
+		if len(cover) == k {
+			return false
+		}
+
+		candidates := append([]Edge{}, incident[next]...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			containedI, sharedI := shared(candidates[i])
+			containedJ, sharedJ := shared(candidates[j])
+			if containedI != containedJ {
+				return containedI
+			}
+			return sharedI > sharedJ
+		})
+
+		for _, e := range candidates {
+			cover = append(cover, e)
+			for _, v := range e.Vertices {
+				covered[v]++
+			}
+			if search() {
+				return true
+			}
+			for _, v := range e.Vertices {
+				covered[v]--
+			}
+			cover = cover[:len(cover)-1]
+		}
+
+		return false
+	}
+
+	if !search() {
+		return Edges{}, false
+	}
+
+	return NewEdges(cover), true
+}
+
+// Restore glues the decomps of all parts into one decomp, undoing the splits in reverse order. Each split is replaced
+// by a node covered by the given cover of its separator, connected to the nodes of the parts containing the separator.
+// The decomps must be given in the same order as the parts, and the covers in the same order as the splits.
+func (s SafeSeps) Restore(decomps []Decomp, covers []Edges) (Node, bool) {
+	if len(decomps) != len(s.Parts) || len(decomps) == 0 || len(covers) != len(s.Ops) {
+		return Node{}, false
+	}
+	for i := range decomps {
+		if reflect.DeepEqual(decomps[i], Decomp{}) {
+			return Node{}, false
+		}
+	}
+
+	roots := make([]Node, len(decomps))
+	for i := range decomps {
+		roots[i] = decomps[i].Root
+	}
+
+	for i := len(s.Ops) - 1; i >= 0; i-- {
+		op := s.Ops[i]
+
+		output := NewNode(op.Sep, covers[i])
+		for _, p := range op.Split {
+			output.Children = append(output.Children, roots[p].RerootEdge(op.Sep))
+		}
+		roots[op.Part] = output
+	}
+
+	return roots[0], true
+}
+
+// DecompSafeSeps computes a decomposition of width ≤ k of the original input graph, decomposing all parts in
+// parallel like DecompBlocks. Within the parts, each separator is represented by the subedges of a cover of it. Unless the separator is
+// a union of edges, the nodes covering it may violate the special condition of hypertree decompositions.
+func (s SafeSeps) DecompSafeSeps(alg AlgorithmH, g Graph, k int) Decomp {
+	incident := make(map[int][]Edge)
+	for _, e := range g.Edges.Slice() {
+		for _, v := range RemoveDuplicates(append([]int{}, e.Vertices...)) {
+			incident[v] = append(incident[v], e)
+		}
+	}
+
+	covers := make([]Edges, len(s.Ops))
+	specials := make([][]Edge, len(s.Ops))
+	for i, op := range s.Ops {
+		cover, ok := coverSeparator(op.Sep, incident, k)
+		if !ok { // the separator is a clique, so it must be covered by a single node
+			return Decomp{}
+		}
+		covers[i] = cover
+		for _, e := range cover.Slice() {
+			if Subset(e.Vertices, op.Sep) {
+				specials[i] = append(specials[i], e)
+			} else {
+				specials[i] = append(specials[i], NewEdge(0, Inter(e.Vertices, op.Sep)))
+			}
+		}
+	}
+
+	parts := make([]Graph, len(s.Parts))
+	for i := range s.Parts {
+		parts[i] = Graph{Edges: s.Parts[i].Edges, Special: append([]Edges{}, s.Parts[i].Special...)}
+		for _, op := range s.seps[i] {
+			parts[i].Special = append(parts[i].Special, NewEdges(specials[op]))
+		}
+	}
+
+	decomps, _, ok := decompAllWith(alg, parts)
+	if !ok {
+		return Decomp{}
+	}
+
+	root, ok := s.Restore(decomps, covers)
+	if !ok {
+		return Decomp{}
+	}
+
+	output := Decomp{Graph: g, Root: root}
+	output.RestoreSubedges()
+
+	return output
+}
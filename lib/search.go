@@ -5,8 +5,6 @@ package lib
 import (
 	"runtime"
 	"sync"
-
-	"github.com/cem-okulmus/disjoint"
 )
 
 // A Search implements a parallel search for separators fulfilling some given predicate
@@ -52,6 +50,153 @@ func (p ParallelSearchGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens
 	}
 }
 
+// DeterministicSearchGen sets up a DeterministicSearch
+type DeterministicSearchGen struct{}
+
+func (d DeterministicSearchGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens []Generator) Search {
+	return &DeterministicSearch{
+		ParallelSearch: ParallelSearch{
+			H:               H,
+			Edges:           Edges,
+			BalFactor:       BalFactor,
+			Result:          []int{},
+			Generators:      Gens,
+			ExhaustedSearch: false,
+		},
+	}
+}
+
+// DeterministicSearch is a parallel search that always returns the results in the same order as a sequential search
+// would. The generators advance in rounds, each checking one element per round, and the result of the first
+// generator to succeed in a round is taken. Since the generators interleave the search space, this is the next
+// result in the order of the search space.
+type DeterministicSearch struct {
+	ParallelSearch
+	start int // the generator holding the earliest element of the current round
+}
+
+// FindNext looks for the next separator satisfying the predicate
+func (s *DeterministicSearch) FindNext(pred Predicate) {
+	s.Result = []int{} // reset result
+
+	workspaces := make([]CompWorkspace, len(s.Generators))
+	active := make([]bool, len(s.Generators))
+	found := make([]bool, len(s.Generators))
+
+	for {
+		var wg sync.WaitGroup
+		wg.Add(len(s.Generators))
+		for i := range s.Generators {
+			go func(i int) {
+				defer wg.Done()
+				gen := s.Generators[i]
+				active[i] = gen.HasNext()
+				found[i] = false
+				if active[i] {
+					sep := GetSubset(*s.Edges, gen.GetNext())
+					found[i] = pred.Check(s.H, &sep, s.BalFactor, &workspaces[i])
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// the generators after the start are one element behind those before it
+		winner := -1
+		anyActive := false
+		for j := range s.Generators {
+			i := (s.start + j) % len(s.Generators)
+			anyActive = anyActive || active[i]
+			if winner == -1 && found[i] {
+				winner = i
+			}
+		}
+
+		if winner == -1 {
+			if !anyActive {
+				s.ExhaustedSearch = true
+				return
+			}
+			for i := range s.Generators {
+				if active[i] {
+					s.Generators[i].Confirm()
+				}
+			}
+			continue
+		}
+
+		// later generators are not confirmed, so their current elements are checked again in the next search
+		for i := s.start; i != winner; i = (i + 1) % len(s.Generators) {
+			if active[i] {
+				s.Generators[i].Confirm()
+			}
+		}
+		s.Result = append(s.Result, s.Generators[winner].GetNext()...)
+		s.Generators[winner].Found()
+		s.Generators[winner].Confirm()
+		s.start = (winner + 1) % len(s.Generators)
+		return
+	}
+}
+
+// CancelSearchGen sets up a search that can be cancelled from the outside: once Cancel is closed, the generators
+// report no further elements, so that any running search ends as if the search space was exhausted
+type CancelSearchGen struct {
+	Cancel  <-chan struct{}
+	Search  SearchGenerator // the search to cancel, a ParallelSearch if nil
+	Workers int             // the number of goroutines a search is split into, taken from Search if 0
+}
+
+func (c CancelSearchGen) GetSearch(H *Graph, Edges *Edges, BalFactor int, Gens []Generator) Search {
+	cancelGens := make([]Generator, len(Gens))
+	for i := range Gens {
+		cancelGens[i] = cancelGenerator{Generator: Gens[i], cancel: c.Cancel}
+	}
+
+	if c.Search == nil {
+		return ParallelSearchGen{}.GetSearch(H, Edges, BalFactor, cancelGens)
+	}
+	return c.Search.GetSearch(H, Edges, BalFactor, cancelGens)
+}
+
+// Workers returns the number of goroutines a search set up by the generator should be split into, which is the share
+// set by a CancelSearchGen, or GOMAXPROCS if there is none
+func Workers(gen SearchGenerator) int {
+	if c, ok := gen.(CancelSearchGen); ok {
+		if c.Workers > 0 {
+			return c.Workers
+		}
+		return Workers(c.Search)
+	}
+	return runtime.GOMAXPROCS(-1)
+}
+
+// Cancelled returns true if the channel has been closed
+func Cancelled(cancel <-chan struct{}) bool {
+	if cancel == nil {
+		return false
+	}
+
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancelGenerator wraps a Generator, ending it once cancel is closed
+type cancelGenerator struct {
+	Generator
+	cancel <-chan struct{}
+}
+
+func (c cancelGenerator) HasNext() bool {
+	if Cancelled(c.cancel) {
+		return false
+	}
+	return c.Generator.HasNext()
+}
+
 // SearchEnded returns true if search is completed
 func (s *ParallelSearch) SearchEnded() bool {
 	return s.ExhaustedSearch
@@ -64,7 +209,7 @@ func (s *ParallelSearch) GetResult() []int {
 
 // A Predicate checks if for some subgraph and a separator, some condition holds
 type Predicate interface {
-	Check(H *Graph, sep *Edges, balancedFactor int, Vertices map[int]*disjoint.Element) bool
+	Check(H *Graph, sep *Edges, balancedFactor int, ws *CompWorkspace) bool
 }
 
 // FindNext starts the search and stops if some separator which satisfies the predicate
@@ -119,7 +264,7 @@ func (s ParallelSearch) worker(workernum int, found chan []int, wg *sync.WaitGro
 		}
 	}()
 	defer wg.Done()
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws CompWorkspace
 
 	gen := s.Generators[workernum]
 
@@ -133,7 +278,7 @@ func (s ParallelSearch) worker(workernum int, found chan []int, wg *sync.WaitGro
 		j := gen.GetNext()
 
 		sep := GetSubset(*s.Edges, j)
-		if pred.Check(s.H, &sep, s.BalFactor, Vertices) {
+		if pred.Check(s.H, &sep, s.BalFactor, &ws) {
 			gen.Found() // cache result
 			found <- j
 			// log.Println("Worker", workernum, "won, found: ", j)
@@ -149,17 +294,13 @@ func (s ParallelSearch) worker(workernum int, found chan []int, wg *sync.WaitGro
 type BalancedCheck struct{}
 
 // Check performs the needed computation to ensure whether sep is a Balanced Separator
-func (b BalancedCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) bool {
+func (b BalancedCheck) Check(H *Graph, sep *Edges, balFactor int, ws *CompWorkspace) bool {
 
 	//balancedness condition
-	comps, _, _ := H.GetComponents(*sep, Vertices)
-
 	balancednessLimit := (((H.Len()) * (balFactor - 1)) / balFactor)
 
-	for i := range comps {
-		if comps[i].Len() > balancednessLimit {
-			return false
-		}
+	if !ws.balanced(H, *sep, balancednessLimit) {
+		return false
 	}
 
 	// Make sure that "special seps can never be used as separators"
@@ -173,10 +314,10 @@ func (b BalancedCheck) Check(H *Graph, sep *Edges, balFactor int, Vertices map[i
 }
 
 // CheckOut does the same as Check, except it also passes on the components found, if output is true
-func (b BalancedCheck) CheckOut(H *Graph, sep *Edges, balFactor int, Vertices map[int]*disjoint.Element) (bool, []Graph, []Edge) {
+func (b BalancedCheck) CheckOut(H *Graph, sep *Edges, balFactor int, ws *CompWorkspace) (bool, []Graph, []Edge) {
 
 	//balancedness condition
-	comps, _, isolated := H.GetComponents(*sep, Vertices)
+	comps, _, isolated := H.GetComponents(*sep, ws)
 
 	balancednessLimit := (((H.Len()) * (balFactor - 1)) / balFactor)
 
@@ -21,13 +21,13 @@ func (s *subSet) hasNext() bool {
 }
 
 func getEdge(vertices []int, s []int) Edge {
-	var output Edge
+	var output []int
 
 	for _, i := range s {
-		output.Vertices = append(output.Vertices, vertices[i])
+		output = append(output, vertices[i])
 	}
 
-	return output
+	return NewEdge(0, output)
 }
 
 func (s *subSet) getCurrent() Edge {
@@ -54,7 +54,7 @@ func getSubEdgeIterator(edges Edges, e Edge, k int) subEdges {
 	for j := range edges.Slice() {
 		inter := Inter(edges.Slice()[j].Vertices, e.Vertices)
 		if len(inter) > 0 && len(inter) < len(e.Vertices) {
-			HEdges = append(HEdges, Edge{Vertices: inter})
+			HEdges = append(HEdges, NewEdge(0, inter))
 		}
 	}
 
@@ -167,7 +167,7 @@ func GetSepSub(edges Edges, sep Edges, k int) *SepSub {
 			}
 		}
 
-		sepIntersectFree = append(sepIntersectFree, Edge{Name: sep.Slice()[i].Name, Vertices: tmpEdge})
+		sepIntersectFree = append(sepIntersectFree, NewEdge(sep.Slice()[i].Name, tmpEdge))
 	}
 
 	newSep := NewEdges(sepIntersectFree)
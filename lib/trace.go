@@ -0,0 +1,188 @@
+package lib
+
+// trace.go records the steps of a search as a trace of JSON lines, and reads such traces back in
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The kinds of events in a trace
+const (
+	TraceEnter     = "enter" // a recursion step starts to decompose a subgraph
+	TraceSeparator = "sep"   // a step chose a separator, or a subedge variant of it, and computed the components
+	TraceExit      = "exit"  // a step returned, either with a decomposition or rejecting the subgraph
+)
+
+// maxTraceLine bounds the length of the lines read by ReadTrace, which grow with the separators and components of a
+// step
+const maxTraceLine = 64 * 1024 * 1024
+
+// A TraceEvent is one event of a search recorded by a Tracer. Events of different steps may be interleaved, as steps
+// run in parallel, but the events of one step are in order.
+type TraceEvent struct {
+	Kind       string   `json:"k"`
+	Step       int64    `json:"s"`               // the recursion step, numbered from 1 in the order of entering
+	Time       int64    `json:"t"`               // ns since the start of the trace
+	Parent     int64    `json:"p,omitempty"`     // enter: the step that called this one, 0 for the top level
+	Depth      int      `json:"d,omitempty"`     // enter: the depth of the step, starting with 1
+	Width      int      `json:"w,omitempty"`     // enter: the width searched for
+	Graph      uint64   `json:"g,omitempty"`     // enter: the hash of the subgraph
+	Edges      int      `json:"e,omitempty"`     // enter: the number of edges and special edges of the subgraph
+	Separator  []int    `json:"sep,omitempty"`   // sep: the names of the edges in the separator, 0 for subedges
+	Subedge    bool     `json:"sub,omitempty"`   // sep: true if the separator is a subedge variant
+	Components []uint64 `json:"comps,omitempty"` // sep: the hashes of the components, without the separator
+	Accepted   bool     `json:"ok,omitempty"`    // exit: true if a decomposition was found
+}
+
+// A Tracer records the steps of a search as JSON lines. All methods can be used by several goroutines at once, and
+// do nothing on a nil Tracer, so that tracing is opt-in.
+type Tracer struct {
+	mux     sync.Mutex
+	out     *bufio.Writer
+	closers []io.Closer
+	steps   int64
+	start   time.Time
+	closed  bool // events of searches still running after Close are dropped
+}
+
+// NewTracer creates a Tracer writing to w
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{out: bufio.NewWriter(w), start: time.Now()}
+}
+
+// CreateTracer creates a Tracer writing to a new file, compressed with gzip if the path ends in .gz
+func CreateTracer(path string) (*Tracer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		output := NewTracer(f)
+		output.closers = []io.Closer{f}
+		return output, nil
+	}
+
+	zipped := gzip.NewWriter(f)
+	output := NewTracer(zipped)
+	output.closers = []io.Closer{zipped, f}
+	return output, nil
+}
+
+func (t *Tracer) write(event TraceEvent) {
+	event.Time = time.Since(t.start).Nanoseconds()
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if !t.closed {
+		t.out.Write(line)
+		t.out.WriteByte('\n')
+	}
+}
+
+// traceHash computes the hash of a graph on fresh copies of its edges, as the hashes cached by Edges.Hash would make
+// the nodes of a decomposition differ from otherwise equal ones under reflect.DeepEqual
+func traceHash(H Graph) uint64 {
+	g := Graph{Edges: NewEdges(H.Edges.Slice())}
+	for i := range H.Special {
+		g.Special = append(g.Special, NewEdges(H.Special[i].Slice()))
+	}
+
+	return g.Hash()
+}
+
+// Enter records the start of a new step decomposing H for some width, and returns the number of the step
+func (t *Tracer) Enter(parent int64, depth int, width int, H Graph) int64 {
+	if t == nil {
+		return 0
+	}
+	step := atomic.AddInt64(&t.steps, 1)
+	t.write(TraceEvent{Kind: TraceEnter, Step: step, Parent: parent, Depth: depth, Width: width, Graph: traceHash(H),
+		Edges: H.Len()})
+
+	return step
+}
+
+// Separator records the separator chosen in a step, and the components it produces
+func (t *Tracer) Separator(step int64, sep Edges, subedge bool, comps []Graph) {
+	if t == nil {
+		return
+	}
+	event := TraceEvent{Kind: TraceSeparator, Step: step, Subedge: subedge}
+	for _, e := range sep.Slice() {
+		event.Separator = append(event.Separator, e.Name)
+	}
+	for i := range comps {
+		event.Components = append(event.Components, traceHash(comps[i]))
+	}
+	t.write(event)
+}
+
+// Exit records the end of a step, and whether it found a decomposition
+func (t *Tracer) Exit(step int64, accepted bool) {
+	if t == nil {
+		return
+	}
+	t.write(TraceEvent{Kind: TraceExit, Step: step, Accepted: accepted})
+}
+
+// Close writes out all recorded events, and closes the file of a Tracer made by CreateTracer
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	err := t.out.Flush()
+	for _, c := range t.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// ReadTrace reads all events of a trace written by a Tracer, decompressing it if it is compressed with gzip
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	in := bufio.NewReader(r)
+	if magic, err := in.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zipped, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		defer zipped.Close()
+		in = bufio.NewReader(zipped)
+	}
+
+	var output []TraceEvent
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxTraceLine)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event TraceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return output, err
+		}
+		output = append(output, event)
+	}
+
+	return output, scanner.Err()
+}
@@ -0,0 +1,258 @@
+package lib
+
+// vertexset.go implements a compact bitset for sets of vertices, used to speed up the set operations on the
+// vertices of edges, bags and covers.
+//
+// Edges built by NewEdge and nodes built by NewNode store their vertices both as a slice, which keeps the order used
+// for the output, and as a set, which is used by FilterVertices, CutEdges, Cover, GetMSCOrder and the checks of a
+// decomposition. For edges and nodes built as literals, the set is computed whenever it is needed. Small slices are
+// compared directly, without building any set (see the benchmarks in test/vertexset_test.go).
+
+import "math/bits"
+
+// A VertexSet is a set of vertices, stored as a bitset. Only the words between the smallest and the largest vertex
+// are kept, so sets over vertices with nearby encodings stay small. The zero value is the empty set.
+type VertexSet struct {
+	base  int      // index of the first word
+	words []uint64 // bit i of word j stands for the vertex 64*(base+j) + i
+}
+
+// NewVertexSet returns the set of the given vertices
+func NewVertexSet(vertices []int) VertexSet {
+	if len(vertices) == 0 {
+		return VertexSet{}
+	}
+
+	lo, hi := vertices[0], vertices[0]
+	for _, v := range vertices[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	s := VertexSet{base: lo >> 6, words: make([]uint64, hi>>6-lo>>6+1)}
+	for _, v := range vertices {
+		s.words[v>>6-s.base] |= 1 << uint(v&63)
+	}
+
+	return s
+}
+
+// word returns the word with the given index, which is zero outside of the stored range
+func (s VertexSet) word(i int) uint64 {
+	if i < s.base || i >= s.base+len(s.words) {
+		return 0
+	}
+	return s.words[i-s.base]
+}
+
+// grow extends the stored range of words to include the index i
+func (s *VertexSet) grow(i int) {
+	if len(s.words) == 0 {
+		s.base = i
+		s.words = make([]uint64, 1)
+		return
+	}
+	if i < s.base {
+		nu := make([]uint64, s.base+len(s.words)-i)
+		copy(nu[s.base-i:], s.words)
+		s.base = i
+		s.words = nu
+	} else if i >= s.base+len(s.words) {
+		nu := make([]uint64, i-s.base+1)
+		copy(nu, s.words)
+		s.words = nu
+	}
+}
+
+// Add inserts the vertex v into the set
+func (s *VertexSet) Add(v int) {
+	s.grow(v >> 6)
+	s.words[v>>6-s.base] |= 1 << uint(v&63)
+}
+
+// Remove deletes the vertex v from the set
+func (s *VertexSet) Remove(v int) {
+	i := v >> 6
+	if i < s.base || i >= s.base+len(s.words) {
+		return
+	}
+	s.words[i-s.base] &^= 1 << uint(v&63)
+}
+
+// Has checks if the vertex v is in the set
+func (s VertexSet) Has(v int) bool {
+	return s.word(v>>6)&(1<<uint(v&63)) != 0
+}
+
+// Len returns the number of vertices in the set
+func (s VertexSet) Len() int {
+	output := 0
+	for _, w := range s.words {
+		output += bits.OnesCount64(w)
+	}
+	return output
+}
+
+// IsEmpty checks if the set contains no vertices
+func (s VertexSet) IsEmpty() bool {
+	for _, w := range s.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the set, which can be modified independently
+func (s VertexSet) Clone() VertexSet {
+	if len(s.words) == 0 {
+		return VertexSet{}
+	}
+	words := make([]uint64, len(s.words))
+	copy(words, s.words)
+	return VertexSet{base: s.base, words: words}
+}
+
+// Union returns the set of vertices in s or o
+func (s VertexSet) Union(o VertexSet) VertexSet {
+	if len(s.words) == 0 {
+		return o.Clone()
+	}
+	if len(o.words) == 0 {
+		return s.Clone()
+	}
+
+	lo := s.base
+	if o.base < lo {
+		lo = o.base
+	}
+	hi := s.base + len(s.words)
+	if o.base+len(o.words) > hi {
+		hi = o.base + len(o.words)
+	}
+
+	output := VertexSet{base: lo, words: make([]uint64, hi-lo)}
+	for i := range output.words {
+		output.words[i] = s.word(lo+i) | o.word(lo+i)
+	}
+
+	return output
+}
+
+// UnionWith adds all vertices of o to s
+func (s *VertexSet) UnionWith(o VertexSet) {
+	if len(o.words) == 0 {
+		return
+	}
+	s.grow(o.base)
+	s.grow(o.base + len(o.words) - 1)
+	for i, w := range o.words {
+		s.words[o.base+i-s.base] |= w
+	}
+}
+
+// Inter returns the set of vertices in both s and o
+func (s VertexSet) Inter(o VertexSet) VertexSet {
+	lo := s.base
+	if o.base > lo {
+		lo = o.base
+	}
+	hi := s.base + len(s.words)
+	if o.base+len(o.words) < hi {
+		hi = o.base + len(o.words)
+	}
+	if lo >= hi {
+		return VertexSet{}
+	}
+
+	output := VertexSet{base: lo, words: make([]uint64, hi-lo)}
+	for i := range output.words {
+		output.words[i] = s.words[lo+i-s.base] & o.words[lo+i-o.base]
+	}
+
+	return output
+}
+
+// Diff returns the set of vertices in s, but not in o
+func (s VertexSet) Diff(o VertexSet) VertexSet {
+	if len(s.words) == 0 {
+		return VertexSet{}
+	}
+
+	output := VertexSet{base: s.base, words: make([]uint64, len(s.words))}
+	for i, w := range s.words {
+		output.words[i] = w &^ o.word(s.base+i)
+	}
+
+	return output
+}
+
+// InterLen returns the number of vertices in both s and o
+func (s VertexSet) InterLen(o VertexSet) int {
+	output := 0
+	for i, w := range s.words {
+		output += bits.OnesCount64(w & o.word(s.base+i))
+	}
+	return output
+}
+
+// Intersects checks if s and o have at least one vertex in common
+func (s VertexSet) Intersects(o VertexSet) bool {
+	for i, w := range s.words {
+		if w&o.word(s.base+i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SubsetOf checks if every vertex of s is also in o
+func (s VertexSet) SubsetOf(o VertexSet) bool {
+	for i, w := range s.words {
+		if w&^o.word(s.base+i) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal checks if s and o contain the same vertices
+func (s VertexSet) Equal(o VertexSet) bool {
+	return s.SubsetOf(o) && o.SubsetOf(s)
+}
+
+// Slice returns the vertices of the set in ascending order
+func (s VertexSet) Slice() []int {
+	output := make([]int, 0, s.Len())
+	for i, w := range s.words {
+		for w != 0 {
+			output = append(output, (s.base+i)<<6+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return output
+}
+
+// HasAll checks if every vertex in the slice vertices is in the set
+func (s VertexSet) HasAll(vertices []int) bool {
+	for _, v := range vertices {
+		if !s.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny checks if some vertex in the slice vertices is in the set
+func (s VertexSet) HasAny(vertices []int) bool {
+	for _, v := range vertices {
+		if s.Has(v) {
+			return true
+		}
+	}
+	return false
+}
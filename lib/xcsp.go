@@ -0,0 +1,289 @@
+package lib
+
+// xcsp.go implements a reader for CSP instances in the XCSP3-core format (see xcsp.org), extracting the
+// hypergraph formed by the scopes of all constraints
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is a generic representation of an XML element, used to walk through XCSP3 instances
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func (n xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// text returns the character data of an element and all its descendants, skipping over the given elements
+func (n xmlNode) text(skip map[string]bool) string {
+	var buffer strings.Builder
+
+	buffer.WriteString(n.Content)
+	for i := range n.Nodes {
+		if skip[n.Nodes[i].XMLName.Local] {
+			continue
+		}
+		buffer.WriteString(" ")
+		buffer.WriteString(n.Nodes[i].text(skip))
+	}
+
+	return buffer.String()
+}
+
+// tuple lists of extensional constraints never mention any variables, and are skipped for performance reasons
+var xcspSkipped = map[string]bool{"supports": true, "conflicts": true}
+
+var xcspToken = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)((?:\[[0-9.]*\])*)$`)
+var xcspIndex = regexp.MustCompile(`\[([0-9.]*)\]`)
+var xcspParameter = regexp.MustCompile(`%([0-9]+)`)
+
+// xcspReader keeps track of the declared variables of an XCSP3 instance, and the scopes extracted so far
+type xcspReader struct {
+	simple map[string]bool  // declared (non-array) variables
+	arrays map[string][]int // declared arrays and their dimensions
+	edges  []parseEdge
+	names  map[string]bool
+}
+
+// GetGraphXCSP parses a string in XCSP3-core format into a graph. Each constraint is turned into an edge, whose
+// vertices are the variables in its scope. The id of a constraint is used as the name of its edge, so that any
+// decomposition can be mapped back to the constraints of the instance. Constraints without an id are named by their
+// position in the instance, and members of groups are named by the id of the group, followed by their index.
+func GetGraphXCSP(s string) (Graph, ParseGraph) {
+	var instance xmlNode
+
+	err := xml.Unmarshal([]byte(s), &instance)
+	if err != nil {
+		fmt.Fprintln(messages, "Couldn't parse input: ")
+		panic(err)
+	}
+
+	if instance.XMLName.Local != "instance" {
+		log.Panicln("Not an XCSP3 instance, root element is", instance.XMLName.Local)
+	}
+
+	r := xcspReader{simple: make(map[string]bool), arrays: make(map[string][]int), names: make(map[string]bool)}
+
+	for _, n := range instance.Nodes {
+		if n.XMLName.Local == "variables" {
+			r.declare(n)
+		}
+	}
+	for _, n := range instance.Nodes {
+		if n.XMLName.Local == "constraints" {
+			r.constraints(n)
+		}
+	}
+
+	pgraph := ParseGraph{Edges: r.edges}
+
+	return pgraph.encodeGraph(), pgraph
+}
+
+// declare records all variables and arrays of variables found in the variables section
+func (r *xcspReader) declare(variables xmlNode) {
+	for _, n := range variables.Nodes {
+		id := n.attr("id")
+		switch n.XMLName.Local {
+		case "var":
+			r.simple[id] = true
+		case "array":
+			var dims []int
+			for _, d := range xcspIndex.FindAllStringSubmatch(n.attr("size"), -1) {
+				size, err := strconv.Atoi(d[1])
+				if err != nil {
+					log.Panicln("Array", id, "has invalid size:", n.attr("size"))
+				}
+				dims = append(dims, size)
+			}
+			r.arrays[id] = dims
+		}
+	}
+}
+
+// constraints extracts the scopes of all constraints, descending into blocks
+func (r *xcspReader) constraints(constraints xmlNode) {
+	for _, n := range constraints.Nodes {
+		switch n.XMLName.Local {
+		case "block":
+			r.constraints(n)
+		case "group":
+			r.group(n)
+		case "slide":
+			r.slide(n)
+		default:
+			r.addEdge(n.attr("id"), r.scope(n.text(xcspSkipped)))
+		}
+	}
+}
+
+// group produces one edge for each args element, using the template constraint for the fixed part of the scope
+func (r *xcspReader) group(group xmlNode) {
+	var template string
+	var args []string
+
+	for _, n := range group.Nodes {
+		if n.XMLName.Local == "args" {
+			args = append(args, n.text(xcspSkipped))
+		} else {
+			template = n.text(xcspSkipped)
+		}
+	}
+
+	id := group.attr("id")
+	for i := range args {
+		name := ""
+		if id != "" {
+			name = id + "[" + strconv.Itoa(i) + "]"
+		}
+		r.addEdge(name, r.scope(template+" "+args[i]))
+	}
+}
+
+// slide produces one edge for each window of the (single) list of variables, moved along by the given offset
+func (r *xcspReader) slide(slide xmlNode) {
+	var list []string
+	var template string
+	offset := 1
+	circular := slide.attr("circular") == "true"
+
+	for _, n := range slide.Nodes {
+		if n.XMLName.Local == "list" {
+			list = append(list, r.scope(n.text(xcspSkipped))...)
+			if o, err := strconv.Atoi(n.attr("offset")); err == nil && o > 0 {
+				offset = o
+			}
+		} else {
+			template = n.text(xcspSkipped)
+		}
+	}
+
+	arity := 0
+	for _, p := range xcspParameter.FindAllStringSubmatch(template, -1) {
+		i, _ := strconv.Atoi(p[1])
+		if i+1 > arity {
+			arity = i + 1
+		}
+	}
+	fixed := r.scope(template)
+
+	id := slide.attr("id")
+	for i, count := 0, 0; i < len(list); i, count = i+offset, count+1 {
+		if !circular && i+arity > len(list) {
+			break
+		}
+		window := append([]string{}, fixed...)
+		for j := 0; j < arity; j++ {
+			window = append(window, list[(i+j)%len(list)])
+		}
+		name := ""
+		if id != "" {
+			name = id + "[" + strconv.Itoa(count) + "]"
+		}
+		r.addEdge(name, removeDuplicateStrings(window))
+	}
+}
+
+// scope collects all declared variables mentioned in a piece of text, in order of their first appearance
+func (r *xcspReader) scope(text string) []string {
+	var output []string
+
+	tokens := strings.FieldsFunc(text, func(c rune) bool {
+		return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == '(' || c == ')'
+	})
+
+	for _, t := range tokens {
+		match := xcspToken.FindStringSubmatch(t)
+		if match == nil {
+			continue
+		}
+		if r.simple[match[1]] && match[2] == "" {
+			output = append(output, match[1])
+			continue
+		}
+		if dims, ok := r.arrays[match[1]]; ok {
+			output = append(output, expandArray(match[1], dims, match[2])...)
+		}
+	}
+
+	return removeDuplicateStrings(output)
+}
+
+// addEdge adds a new edge for a scope, unless the scope is empty. Unnamed edges are given a fresh name
+func (r *xcspReader) addEdge(name string, scope []string) {
+	if len(scope) == 0 {
+		return
+	}
+
+	if name == "" {
+		name = "c" + strconv.Itoa(len(r.edges))
+	}
+	for r.names[name] || r.simple[name] {
+		name = name + "'"
+	}
+	r.names[name] = true
+
+	r.edges = append(r.edges, parseEdge{Name: name, Vertices: scope})
+}
+
+// expandArray produces the names of all array elements matched by an index pattern, such as x[][2] or x[0..3]
+func expandArray(id string, dims []int, pattern string) []string {
+	indices := xcspIndex.FindAllStringSubmatch(pattern, -1)
+	if len(indices) > len(dims) {
+		return []string{}
+	}
+
+	output := []string{id}
+
+	for d := range dims {
+		low, high := 0, dims[d]-1
+		if d < len(indices) && indices[d][1] != "" {
+			bounds := strings.SplitN(indices[d][1], "..", 2)
+			low, _ = strconv.Atoi(bounds[0])
+			high = low
+			if len(bounds) == 2 {
+				high, _ = strconv.Atoi(bounds[1])
+			}
+		}
+
+		var next []string
+		for _, prefix := range output {
+			for i := low; i <= high && i < dims[d]; i++ {
+				next = append(next, prefix+"["+strconv.Itoa(i)+"]")
+			}
+		}
+		output = next
+	}
+
+	return output
+}
+
+// removeDuplicateStrings removes any repeated occurrences in a slice of strings, keeping the original order
+func removeDuplicateStrings(elements []string) []string {
+	var output []string
+	encountered := make(map[string]bool)
+
+	for _, e := range elements {
+		if !encountered[e] {
+			encountered[e] = true
+			output = append(output, e)
+		}
+	}
+
+	return output
+}
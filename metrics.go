@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+)
+
+// metric is a single metric in the Prometheus text exposition format
+type metric struct {
+	name   string
+	help   string
+	kind   string // counter or gauge
+	values []labelledValue
+}
+
+// labelledValue is one sample of a metric, with an optional label
+type labelledValue struct {
+	label string // written as is between braces, e.g. level="1", empty if the sample has no labels
+	value float64
+}
+
+func (m metric) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+	for _, v := range m.values {
+		value := strconv.FormatFloat(v.value, 'g', -1, 64)
+		if v.label != "" {
+			fmt.Fprintf(w, "%s{%s} %s\n", m.name, v.label, value)
+		} else {
+			fmt.Fprintf(w, "%s %s\n", m.name, value)
+		}
+	}
+}
+
+// single creates a metric with one sample without labels
+func single(name, help, kind string, value float64) metric {
+	return metric{name: name, help: help, kind: kind, values: []labelledValue{{value: value}}}
+}
+
+// collectMetrics produces the metrics for the current state of the counters, and the width currently searched for
+func collectMetrics(counters *algo.Counters, width int64) []metric {
+	p := counters.Progress()
+
+	output := []metric{
+		single("balancedgo_separators_tested_total", "Separators tested at any level.", "counter",
+			float64(p.Separators)),
+		single("balancedgo_balanced_separators_found_total", "Separators that passed the check of a separator search.",
+			"counter", float64(p.Balanced)),
+		single("balancedgo_subedges_tried_total", "Subedge variants of separators tried.", "counter",
+			float64(p.Subedges)),
+		single("balancedgo_cache_lookups_total", "Lookups in the cache of negative results.", "counter",
+			float64(p.CacheLookups)),
+		single("balancedgo_cache_hits_total", "Lookups in the cache which allowed to skip a separator.", "counter",
+			float64(p.CacheHits)),
+		single("balancedgo_cache_entries_total", "Negative results added to the cache.", "counter",
+			float64(p.CacheEntries)),
+		single("balancedgo_cache_size", "Current number of bindings in the caches of the searches still running.",
+			"gauge", float64(p.CacheSize)),
+	}
+
+	backtracks := metric{name: "balancedgo_backtracks_total", kind: "counter",
+		help: "Backtracks per level, starting with 1 for the top level."}
+	var levels []int
+	for level := range p.Backtracks {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	for _, level := range levels {
+		backtracks.values = append(backtracks.values,
+			labelledValue{label: "level=\"" + strconv.Itoa(level) + "\"", value: float64(p.Backtracks[level])})
+	}
+	output = append(output, backtracks)
+
+	if p.Completion >= 0 {
+		output = append(output, single("balancedgo_toplevel_completion",
+			"Share of the search space of the current top-level separator search checked.", "gauge", p.Completion))
+	}
+	output = append(output,
+		single("balancedgo_width", "The width currently searched for.", "gauge", float64(width)),
+		single("balancedgo_goroutines", "Number of goroutines.", "gauge", float64(runtime.NumGoroutine())))
+
+	return output
+}
+
+// serveMetrics exposes the metrics on the given address, such as :9090, under /metrics. It returns once the address
+// is bound, while the metrics are served in the background until the program ends.
+func serveMetrics(addr string, counters *algo.Counters, width *int64) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		for _, m := range collectMetrics(counters, atomic.LoadInt64(width)) {
+			m.write(w)
+		}
+	})
+
+	go http.Serve(listener, mux)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestAcyclicity checks the notions of acyclicity on graphs separating them
+func TestAcyclicity(t *testing.T) {
+	tests := []struct {
+		graph    string
+		expected lib.Acyclicity
+	}{
+		{"E1(a,b), E2(b,c), E3(c,d).", lib.Acyclicity{Alpha: true, Beta: true, Gamma: true}},
+		{"E1(a,b,c), E2(a,b), E3(b,c).", lib.Acyclicity{Alpha: true, Beta: true, Gamma: false}},
+		{"E1(a,b,c), E2(a,b), E3(b,c), E4(a,c).", lib.Acyclicity{Alpha: true, Beta: false, Gamma: false}},
+		{"E1(a,b), E2(b,c), E3(c,a).", lib.Acyclicity{}},
+	}
+
+	for _, test := range tests {
+		graph, _ := lib.GetGraph(test.graph)
+
+		if acyclicity := graph.GetAcyclicity(); acyclicity != test.expected {
+			t.Errorf("Expected %v to be %v, got %v", graph, test.expected, acyclicity)
+		}
+	}
+}
+
+// TestAcyclicityRandom checks on random graphs that the notions of acyclicity imply each other, that β-acyclic graphs
+// have only α-acyclic subsets of edges, and that α-acyclic graphs have a join tree
+func TestAcyclicityRandom(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		graph, _ := getRandomGraph(8)
+
+		alpha, beta, gamma := graph.IsAlphaAcyclic(), graph.IsBetaAcyclic(), graph.IsGammaAcyclic()
+		if (gamma && !beta) || (beta && !alpha) {
+			t.Errorf("Inconsistent acyclicity for %v: α %v, β %v, γ %v", graph, alpha, beta, gamma)
+		}
+
+		allSubsetsAcyclic := true
+		edges := graph.Edges.Slice()
+		for subset := 1; subset < 1<<uint(len(edges)); subset++ {
+			var chosen []lib.Edge
+			for j := range edges {
+				if subset&(1<<uint(j)) > 0 {
+					chosen = append(chosen, edges[j])
+				}
+			}
+			if !(lib.Graph{Edges: lib.NewEdges(chosen)}).IsAlphaAcyclic() {
+				allSubsetsAcyclic = false
+				break
+			}
+		}
+		if beta != allSubsetsAcyclic {
+			t.Errorf("β-acyclicity of %v is %v, but all subsets α-acyclic is %v", graph, beta, allSubsetsAcyclic)
+		}
+
+		joinTree, ok := graph.GetJoinTree()
+		if ok != alpha {
+			t.Errorf("Join tree of %v found: %v, but α-acyclic: %v", graph, ok, alpha)
+		}
+		if ok && (!joinTree.Correct(graph) || joinTree.CheckWidth() != 1) {
+			t.Errorf("Incorrect join tree %v for %v", joinTree, graph)
+		}
+	}
+}
+
+// TestJoinTreeShortcut checks that join trees are used for acyclic graphs, unless join costs are considered
+func TestJoinTreeShortcut(t *testing.T) {
+	acyclic, _ := lib.GetGraph("R(a,b,c), S(c,d), T(d,e,f), U(f,g), V(a,b,h).")
+	cyclic, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a).")
+
+	det := &algo.DetKDecomp{K: 2, Graph: acyclic, BalFactor: 2}
+	if _, ok := lib.JoinTreeShortcut(det, acyclic); !ok {
+		t.Error("No join tree used for acyclic graph")
+	}
+	if _, ok := lib.JoinTreeShortcut(det, cyclic); ok {
+		t.Error("Join tree used for cyclic graph")
+	}
+
+	decomp := lib.DecompGraph(det, acyclic)
+	if !decomp.Correct(acyclic) || decomp.CheckWidth() != 1 {
+		t.Errorf("Incorrect join tree: %v", decomp)
+	}
+
+	costs := &algo.JCostBalSepLocal{K: 2, Graph: acyclic, BalFactor: 2, JCosts: &lib.EdgesCostMap{}}
+	if _, ok := lib.JoinTreeShortcut(costs, acyclic); ok {
+		t.Error("Join tree used despite join costs")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"reflect"
 	"testing"
-	"time"
 
 	algo "github.com/cem-okulmus/BalancedGo/algorithms"
 	"github.com/cem-okulmus/BalancedGo/lib"
@@ -46,7 +45,7 @@ func TestAlgo(t *testing.T) {
 
 	// logActive(false)
 
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	graphInitial, encoding := getRandomGraph(10)
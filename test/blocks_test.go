@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestSplitBlocks checks the blocks of a small graph, consisting of two cycles sharing a vertex and a path
+func TestSplitBlocks(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a), U(c,d), V(d,e), W(e,c), X(e,f), Y(f,g).")
+
+	blocks := lib.SplitBlocks(graph)
+
+	// {R,S,T}, {U,V,W}, {X}, {Y}
+	if blocks.Len() != 4 {
+		t.Errorf("Expected 4 blocks, got %v: %v", blocks.Len(), blocks.Blocks)
+	}
+
+	edges := 0
+	for _, b := range blocks.Blocks {
+		edges = edges + b.Edges.Len()
+	}
+	if edges != graph.Edges.Len() {
+		t.Errorf("Blocks contain %v edges, expected %v", edges, graph.Edges.Len())
+	}
+
+	solver := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	decomp := blocks.DecompBlocks(solver, graph)
+
+	if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+		t.Errorf("Incorrect decomposition from blocks: %v", decomp)
+	}
+}
+
+// TestDecompBlocks compares decomposing via blocks with decomposing the entire graph on random graphs
+func TestDecompBlocks(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+		blocks := lib.SplitBlocks(graph)
+
+		for k := 2; k <= 3; k++ { // the base cases of BalSep assume a width of at least 2
+			solver := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}
+			solver.SetGenerator(lib.ParallelSearchGen{})
+
+			direct := solver.FindDecomp()
+			viaBlocks := blocks.DecompBlocks(solver, graph)
+
+			if reflect.DeepEqual(direct, lib.Decomp{}) != reflect.DeepEqual(viaBlocks, lib.Decomp{}) {
+				t.Errorf("Blocks disagree on existence of decomposition of width %v for %v", k, graph)
+			}
+			if !reflect.DeepEqual(viaBlocks, lib.Decomp{}) && (!viaBlocks.Correct(graph) || viaBlocks.CheckWidth() > k) {
+				t.Errorf("Incorrect decomposition from blocks: %v", viaBlocks)
+			}
+		}
+	}
+}
+
+// exclusiveAlg wraps an algorithm which can't be copied, recording whether it was ever used by several goroutines
+// at once
+type exclusiveAlg struct {
+	lib.AlgorithmH
+	running    int32
+	concurrent int32
+}
+
+func (e *exclusiveAlg) FindDecompGraph(G lib.Graph) lib.Decomp {
+	if atomic.AddInt32(&e.running, 1) > 1 {
+		atomic.StoreInt32(&e.concurrent, 1)
+	}
+	defer atomic.AddInt32(&e.running, -1)
+
+	time.Sleep(time.Millisecond)
+	return e.AlgorithmH.FindDecompGraph(G)
+}
+
+// TestDecompBlocksCopies checks that blocks are decomposed by copies of the solver, which can be reused for
+// rejections and decompositions, and that solvers which can't be copied are used by one block at a time
+func TestDecompBlocksCopies(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a), U(c,d), V(d,e), W(e,c), X(e,f), Y(f,g).")
+	blocks := lib.SplitBlocks(graph)
+
+	det := &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}
+	exclusive := &exclusiveAlg{AlgorithmH: &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}}
+
+	for _, solver := range []lib.AlgorithmH{det, exclusive} {
+		for i := 0; i < 3; i++ {
+			solver.SetWidth(1)
+			if decomp := blocks.DecompBlocks(solver, graph); !reflect.DeepEqual(decomp, lib.Decomp{}) {
+				t.Errorf("%v: expected rejection for width 1, got %v", solver.Name(), decomp)
+			}
+
+			solver.SetWidth(2)
+			decomp := blocks.DecompBlocks(solver, graph)
+			if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+				t.Errorf("%v: incorrect decomposition from blocks: %v", solver.Name(), decomp)
+			}
+		}
+	}
+
+	if atomic.LoadInt32(&exclusive.concurrent) != 0 {
+		t.Error("Solver which can't be copied used by several blocks at once")
+	}
+}
@@ -5,17 +5,15 @@ package tests
 import (
 	"math/rand"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 )
 
 var EDGE int
 
 //getRandomEdge will produce a random Edge
 func getRandomEdge(size int) lib.Edge {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	arity := r.Intn(size) + 1
@@ -33,7 +31,7 @@ func getRandomEdge(size int) lib.Edge {
 
 //getRandomGraph will produce a random Graph
 func getRandomGraph(size int) (lib.Graph, map[string]int) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 	card := r.Intn(size) + 1
 
@@ -52,7 +50,7 @@ func getRandomGraph(size int) (lib.Graph, map[string]int) {
 
 //getRandomEdges will produce a random Edges struct
 func getRandomEdges(size int) lib.Edges {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	k := r.Intn(size) + 1
@@ -65,7 +63,7 @@ func getRandomEdges(size int) lib.Edges {
 }
 
 func getRandomSep(g lib.Graph, size int) lib.Edges {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	k := r.Intn(size) + 1
@@ -87,10 +85,10 @@ func TestCache(t *testing.T) {
 	var cache lib.Cache
 	var cacheCopy lib.Cache
 
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
 	cache.CopyRef(&cacheCopy)
 
-	comps, _, _ := randomGraph.GetComponents(randomSep, Vertices)
+	comps, _, _ := randomGraph.GetComponents(randomSep, &ws)
 
 	if len(comps) == 0 { // randomSep covers the entire hypergraph. Nothing you can do
 		return
@@ -6,7 +6,6 @@ import (
 	"reflect"
 	"runtime"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
@@ -17,7 +16,7 @@ func TestCombin(t *testing.T) {
 
 	for x := 0; x < 10; x++ {
 
-		s := rand.NewSource(time.Now().UnixNano())
+		s := rand.NewSource(nextSeed())
 		r := rand.New(s)
 
 		randGraph, _ := getRandomGraph(20)
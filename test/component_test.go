@@ -4,7 +4,6 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 	"github.com/cem-okulmus/disjoint"
@@ -50,17 +49,17 @@ func connected(g lib.Graph) bool {
 // TestComponent makes sure the calculation of connected components works. This is done by  generating a random instance of a graph and a separator, and making sure the produced components fulfill th properties of being components of the seperator.
 func TestComponents(t *testing.T) {
 
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	graphInitial, _ := getRandomGraph(30)
 
 	width := r.Intn(6) + 1
 	sep := getRandomSep(graphInitial, width)
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
 
 	// get components
-	comps, _, _ := graphInitial.GetComponents(sep, Vertices)
+	comps, _, _ := graphInitial.GetComponents(sep, &ws)
 
 	// number of components must be >1
 
@@ -105,3 +104,74 @@ func TestComponents(t *testing.T) {
 	}
 
 }
+
+const balFactor = 2
+
+// TestBalancedCheck compares the allocation-free balancedness check against the sizes of the
+// components produced by GetComponents, reusing a single workspace across different graphs.
+func TestBalancedCheck(t *testing.T) {
+	var ws lib.CompWorkspace
+	var check lib.BalancedCheck
+
+	for i := 0; i < 200; i++ {
+		graph, _ := getRandomGraph(20)
+		sep := getRandomSep(graph, 4)
+
+		comps, _, _ := graph.GetComponents(sep, &ws)
+		limit := (graph.Len() * (balFactor - 1)) / balFactor
+
+		expected := true
+		for j := range comps {
+			if comps[j].Len() > limit {
+				expected = false
+			}
+		}
+
+		if out := check.Check(&graph, &sep, balFactor, &ws); out != expected {
+			t.Errorf("balanced check mismatch on graph %v, sep %v: got %v, expected %v", graph, sep, out, expected)
+		}
+	}
+}
+
+// TestBalancedCheckAllocs makes sure that the balancedness check does not allocate once its workspace is set up.
+func TestBalancedCheckAllocs(t *testing.T) {
+	var ws lib.CompWorkspace
+	var check lib.BalancedCheck
+
+	graph, _ := getRandomGraph(50)
+	sep := getRandomSep(graph, 4)
+	check.Check(&graph, &sep, balFactor, &ws)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		check.Check(&graph, &sep, balFactor, &ws)
+	})
+
+	if allocs != 0 {
+		t.Errorf("balanced check allocated %v times per run", allocs)
+	}
+}
+
+func BenchmarkGetComponents(b *testing.B) {
+	var ws lib.CompWorkspace
+	graph, _ := getRandomGraph(100)
+	sep := getRandomSep(graph, 4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.GetComponents(sep, &ws)
+	}
+}
+
+func BenchmarkBalancedCheck(b *testing.B) {
+	var ws lib.CompWorkspace
+	var check lib.BalancedCheck
+	graph, _ := getRandomGraph(100)
+	sep := getRandomSep(graph, 4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		check.Check(&graph, &sep, balFactor, &ws)
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestStatsCostModel checks the estimates of the cost model against manually computed join sizes
+func TestStatsCostModel(t *testing.T) {
+	graph, pGraph := lib.GetGraph("R(x,y), S(y,z), T(z,w), U(w,x).")
+	enc := pGraph.Encoding
+
+	stats := []byte(`{
+		"R": {"rows": 1000, "distinct": {"x": 100, "y": 10}},
+		"S": {"rows": 100, "distinct": {"y": 50, "z": 100}},
+		"T": {"rows": 10}
+	}`)
+
+	model, err := lib.GetStatsCostModel(stats, graph, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		comb []int
+		cost float64
+	}{
+		{[]int{enc["R"]}, 1000},
+		{[]int{enc["U"]}, lib.DefaultRows},
+		{[]int{enc["R"], enc["S"]}, 1000 * 100 / 50},
+		{[]int{enc["S"], enc["R"]}, 1000 * 100 / 50},
+		{[]int{enc["R"], enc["T"]}, 1000 * 10},
+		// T ⋈ S = 10 * 100 / 100 = 10 rows, then joined with R: 10 * 1000 / max(10, 10) = 1000 rows
+		{[]int{enc["R"], enc["S"], enc["T"]}, 10 + 1000},
+	}
+
+	var wg sync.WaitGroup
+	for i := range tests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cost := model.Cost(tests[i].comb)
+			if math.Abs(cost-tests[i].cost) > 0.0001 {
+				t.Errorf("Wrong cost for %v: expected %v, got %v", tests[i].comb, tests[i].cost, cost)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, err = lib.GetStatsCostModel([]byte(`{"V": {"rows": 10}}`), graph, enc)
+	if err == nil {
+		t.Error("Statistics for unknown relation not rejected")
+	}
+}
+
+// TestJCostStats checks that the local join cost algorithm produces correct decompositions using the estimator
+func TestJCostStats(t *testing.T) {
+	graph, pGraph := lib.GetGraph("R(a,b,c), S(c,d,e), T(e,f,a), U(b,d,f), V(a,d).")
+
+	stats := []byte(`{"R": {"rows": 50}, "S": {"rows": 5000}, "T": {"rows": 500, "distinct": {"a": 5}}}`)
+	model, err := lib.GetStatsCostModel(stats, graph, pGraph.Encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solver := &algo.JCostBalSepLocal{K: 2, Graph: graph, BalFactor: 2, JCosts: model}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	decomp := solver.FindDecomp()
+
+	if reflect.DeepEqual(decomp, lib.Decomp{}) || !decomp.Correct(graph) {
+		t.Errorf("No correct decomposition found: %v", decomp)
+	}
+}
+
+// TestJCostVariants checks that the cost aware hybrid and global algorithms produce correct, cost annotated
+// decompositions
+func TestJCostVariants(t *testing.T) {
+	graph, pGraph := lib.GetGraph("R(a,b,c), S(c,d,e), T(e,f,a), U(b,d,f), V(a,d), W(f,g), X(g,h,a).")
+
+	stats := []byte(`{"R": {"rows": 50}, "S": {"rows": 5000}, "T": {"rows": 500, "distinct": {"a": 5}}}`)
+	model, err := lib.GetStatsCostModel(stats, graph, pGraph.Encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	global := graph.ComputeSubEdges(2)
+
+	tests := []struct {
+		solver algo.Algorithm
+		graph  lib.Graph
+	}{
+		{&algo.JCostBalSepHybrid{K: 2, Graph: graph, BalFactor: 2, Depth: 0, JCosts: model}, graph},
+		{&algo.JCostBalSepHybrid{K: 2, Graph: graph, BalFactor: 2, Depth: 1, JCosts: model}, graph},
+		{&algo.JCostBalSepGlobal{K: 2, Graph: global, BalFactor: 2, JCosts: model}, global},
+	}
+
+	for _, test := range tests {
+		test.solver.SetGenerator(lib.ParallelSearchGen{})
+		decomp := test.solver.FindDecomp()
+
+		if reflect.DeepEqual(decomp, lib.Decomp{}) {
+			t.Errorf("%v: no decomposition found", test.solver.Name())
+			continue
+		}
+		if !decomp.Correct(test.graph) || decomp.TotalCost() <= 0 {
+			t.Errorf("%v: incorrect or uncosted decomposition: %v", test.solver.Name(), decomp)
+		}
+	}
+}
+
+// TestJCostOpt checks that the optimal cost search is never worse than the cost guided search, and that the
+// alternatives are ordered by their costs
+func TestJCostOpt(t *testing.T) {
+	graph, pGraph := lib.GetGraph("R(a,b,c), S(c,d,e), T(e,f,a), U(b,d,f), V(a,d), W(f,g), X(g,h,a), Y(h,b).")
+
+	stats := []byte(`{
+		"R": {"rows": 50, "distinct": {"a": 50, "b": 5}},
+		"S": {"rows": 5000, "distinct": {"c": 10}},
+		"T": {"rows": 500, "distinct": {"a": 5}},
+		"U": {"rows": 20},
+		"X": {"rows": 100000, "distinct": {"g": 1000, "h": 10}}
+	}`)
+	model, err := lib.GetStatsCostModel(stats, graph, pGraph.Encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guided := &algo.JCostBalSepLocal{K: 2, Graph: graph, BalFactor: 2, JCosts: model}
+	guided.SetGenerator(lib.ParallelSearchGen{})
+	guidedDecomp := guided.FindDecomp()
+
+	opt := &algo.JCostOptBalSepLocal{K: 2, Graph: graph, BalFactor: 2, JCosts: model, TopN: 3}
+	opt.SetGenerator(lib.ParallelSearchGen{})
+	optDecomp := opt.FindDecomp()
+
+	if reflect.DeepEqual(optDecomp, lib.Decomp{}) || !optDecomp.Correct(graph) {
+		t.Fatalf("No correct decomposition found: %v", optDecomp)
+	}
+	if !reflect.DeepEqual(guidedDecomp, lib.Decomp{}) && optDecomp.TotalCost() > guidedDecomp.TotalCost() {
+		t.Errorf("Optimal cost %v worse than guided cost %v", optDecomp.TotalCost(), guidedDecomp.TotalCost())
+	}
+
+	top := opt.Alternatives()
+	if len(top) == 0 || len(top) > 3 {
+		t.Fatalf("Wrong number of alternatives: %v", len(top))
+	}
+	if top[0].TotalCost() != optDecomp.TotalCost() {
+		t.Errorf("Best alternative has cost %v, expected %v", top[0].TotalCost(), optDecomp.TotalCost())
+	}
+	for i := range top {
+		if !top[i].Correct(graph) {
+			t.Errorf("Alternative %v is not correct: %v", i, top[i])
+		}
+		if i > 0 && top[i-1].TotalCost() > top[i].TotalCost() {
+			t.Errorf("Alternatives not ordered by cost")
+		}
+	}
+}
@@ -3,14 +3,13 @@ package tests
 import (
 	"math/rand"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
 
 //TestCover simply checks if cover can still run to the end, for a random input graph
 func TestCover(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	graph, _ := getRandomGraph(100)
@@ -26,12 +25,12 @@ func TestCover(t *testing.T) {
 }
 
 func shuffle(input lib.Edges) lib.Edges {
-	rand.Seed(time.Now().UTC().UnixNano())
+	r := rand.New(rand.NewSource(nextSeed()))
 	a := make([]lib.Edge, len(input.Slice()))
 	copy(a, input.Slice())
 
 	for i := len(a) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		a[i], a[j] = a[j], a[i]
 	}
 
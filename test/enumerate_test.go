@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestCanonicalHash checks that the canonical hash is invariant under rerooting, reordering and merging of nodes
+func TestCanonicalHash(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,d), U(d,e).")
+	edges := graph.Edges.Slice()
+
+	node := func(e ...lib.Edge) lib.Node {
+		cover := lib.NewEdges(e)
+		return lib.Node{Bag: cover.Vertices(), Cover: cover}
+	}
+
+	// a path R - S - T - U
+	u := node(edges[3])
+	tNode := node(edges[2])
+	tNode.Children = []lib.Node{u}
+	s := node(edges[1])
+	s.Children = []lib.Node{tNode}
+	r := node(edges[0])
+	r.Children = []lib.Node{s}
+
+	path := lib.Decomp{Graph: graph, Root: r}
+	rerooted := lib.Decomp{Graph: graph, Root: r.Reroot(tNode)}
+
+	if path.CanonicalHash() != rerooted.CanonicalHash() {
+		t.Errorf("Hash not invariant under rerooting: %v, %v", path, rerooted)
+	}
+
+	// rooted at S, with children in both orders
+	sRoot := node(edges[1])
+	sRoot.Children = []lib.Node{node(edges[0]), tNode}
+	sRootSwapped := node(edges[1])
+	sRootSwapped.Children = []lib.Node{tNode, node(edges[0])}
+
+	if path.CanonicalHash() != (lib.Decomp{Graph: graph, Root: sRoot}).CanonicalHash() ||
+		path.CanonicalHash() != (lib.Decomp{Graph: graph, Root: sRootSwapped}).CanonicalHash() {
+		t.Error("Hash not invariant under the order of children")
+	}
+
+	// adding a node whose bag is contained in its neighbour
+	extra := lib.Node{Bag: []int{edges[3].Vertices[0]}, Cover: lib.NewEdges([]lib.Edge{edges[3]})}
+	uExtended := node(edges[3])
+	uExtended.Children = []lib.Node{extra}
+	tExtended := node(edges[2])
+	tExtended.Children = []lib.Node{uExtended}
+	sExtended := node(edges[1])
+	sExtended.Children = []lib.Node{tExtended}
+	rExtended := node(edges[0])
+	rExtended.Children = []lib.Node{sExtended}
+
+	if path.CanonicalHash() != (lib.Decomp{Graph: graph, Root: rExtended}).CanonicalHash() {
+		t.Error("Hash not invariant under merging of nodes")
+	}
+
+	// a different tree: R - S, S - U, U - T
+	other := node(edges[0])
+	otherS := node(edges[1])
+	otherU := node(edges[3])
+	otherU.Children = []lib.Node{node(edges[2])}
+	otherS.Children = []lib.Node{otherU}
+	other.Children = []lib.Node{otherS}
+
+	if path.CanonicalHash() == (lib.Decomp{Graph: graph, Root: other}).CanonicalHash() {
+		t.Error("Different decomps have the same hash")
+	}
+}
+
+// TestEnumerator checks that all enumerated decompositions are correct and distinct
+func TestEnumerator(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b,c), S(c,d,e), T(e,f,a), U(b,d,f), V(a,d), W(f,g), X(g,h,a).")
+
+	enum := &algo.Enumerator{K: 2, Graph: graph, BalFactor: 2}
+	seen := make(map[uint64]bool)
+
+	for enum.HasNext() {
+		decomp := enum.GetNext()
+		if !decomp.Correct(graph) {
+			t.Errorf("Incorrect decomposition: %v", decomp)
+		}
+		if decomp.CheckWidth() > 2 {
+			t.Errorf("Decomposition of width %v: %v", decomp.CheckWidth(), decomp)
+		}
+		if seen[decomp.CanonicalHash()] {
+			t.Errorf("Decomposition enumerated twice: %v", decomp)
+		}
+		seen[decomp.CanonicalHash()] = true
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("Expected several decompositions, found %v", len(seen))
+	}
+
+	// stopping early
+	enum = &algo.Enumerator{K: 2, Graph: graph, BalFactor: 2}
+	if !enum.HasNext() {
+		t.Fatal("No decomposition found")
+	}
+	enum.Stop()
+	if enum.HasNext() {
+		t.Error("Enumeration continued after being stopped")
+	}
+}
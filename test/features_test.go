@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestGetFeatures checks the features of a small graph, consisting of a triangle with a path attached
+func TestGetFeatures(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a), U(c,d,e).")
+	features := lib.GetFeatures(graph)
+
+	expected := lib.Features{
+		Vertices:        5,
+		Edges:           4,
+		AvgArity:        9.0 / 4.0,
+		MaxArity:        3,
+		SumArity:        9,
+		AvgDegree:       9.0 / 5.0,
+		MaxDegree:       3,
+		BIP:             1,
+		ReducedVertices: 3,
+		ReducedEdges:    3,
+	}
+	features.DegreeStdDev = 0 // not compared
+	features.Hinges = 0       // not compared
+
+	if features != expected {
+		t.Errorf("Expected features %v, got %v", expected, features)
+	}
+}
+
+// TestDecisionTree checks parsing and following a decision tree
+func TestDecisionTree(t *testing.T) {
+	if _, err := lib.GetDecisionTree([]byte(lib.DefaultDecisionTree)); err != nil {
+		t.Errorf("Default decision tree invalid: %v", err)
+	}
+
+	invalid := []string{
+		`{"feature": "colour", "threshold": 1, "below": {"choice": {"algorithm": "det"}},
+			"above": {"choice": {"algorithm": "det"}}}`,
+		`{"feature": "edges", "threshold": 1, "below": {"choice": {"algorithm": "det"}}}`,
+		`{"choice": {"algorithm": "magic"}}`,
+		`{"choice": {"algorithm": "balDet", "heuristic": 1}}`,
+		`{"choice": {"algorithm": "seqBalDet", "heuristic": 1, "depth": 0}}`,
+		`{"choice": {"algorithm": "det", "heuristic": 9}}`,
+		`{"choice": {"algorithm": "local", "heuristic": -1}}`,
+	}
+	for _, data := range invalid {
+		if _, err := lib.GetDecisionTree([]byte(data)); err == nil {
+			t.Errorf("No error for invalid decision tree %v", data)
+		}
+	}
+
+	tree, err := lib.GetDecisionTree([]byte(`{"feature": "edges", "threshold": 3,
+		"below": {"choice": {"algorithm": "det", "heuristic": 1}},
+		"above": {"choice": {"algorithm": "balDet", "heuristic": 2, "depth": 2}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small, _ := lib.GetGraph("R(a,b), S(b,c).")
+	large, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a), U(c,d,e).")
+
+	if choice := tree.Decide(lib.GetFeatures(small)); choice != (lib.Choice{Algorithm: "det", Heuristic: 1}) {
+		t.Errorf("Wrong choice for small graph: %v", choice)
+	}
+	if choice := tree.Decide(lib.GetFeatures(large)); choice != (lib.Choice{Algorithm: "balDet", Heuristic: 2, Depth: 2}) {
+		t.Errorf("Wrong choice for large graph: %v", choice)
+	}
+}
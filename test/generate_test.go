@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/lib/generate"
+)
+
+// TestGenerateFormats makes sure that generated hypergraphs can be parsed back in both output formats, and that the
+// same seed always produces the same hypergraph
+func TestGenerateFormats(t *testing.T) {
+	seed := nextSeed()
+	graphs := func(r *rand.Rand) []generate.Hypergraph {
+		return []generate.Hypergraph{
+			generate.Uniform(r, 20, 15, 3),
+			generate.PowerLaw(r, 20, 15, 4, 1.5),
+			generate.Grid(3, 4),
+			generate.Cycle(6),
+			generate.Clique(5),
+			generate.HypertreeWidth(r, 2, 6, 3),
+			generate.CQ(r, 8, 10, 3),
+		}
+	}
+
+	first := graphs(rand.New(rand.NewSource(seed)))
+	if second := graphs(rand.New(rand.NewSource(seed))); !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed %v produced different hypergraphs", seed)
+	}
+
+	for _, h := range first {
+		graph, _ := lib.GetGraph(h.HyperBench())
+		if graph.Edges.Len() != len(h.Edges) {
+			t.Errorf("Parsed %v edges from HyperBench output, expected %v:\n%v", graph.Edges.Len(), len(h.Edges),
+				h.HyperBench())
+		}
+
+		pace := lib.GetGraphPACE(h.PACE())
+		if pace.Edges.Len() != len(h.Edges) || len(pace.Edges.Vertices()) != len(graph.Edges.Vertices()) {
+			t.Errorf("PACE output doesn't match HyperBench output:\n%v\n%v", h.PACE(), h.HyperBench())
+		}
+	}
+}
+
+// TestGenerateKnownWidth checks that the hypertree width of the generated families is the one they are built for
+func TestGenerateKnownWidth(t *testing.T) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	tests := []struct {
+		graph generate.Hypergraph
+		width int
+	}{
+		{generate.Cycle(5), 2},
+		{generate.Clique(4), 2},
+		{generate.HypertreeWidth(r, 1, 6, 3), 1},
+		{generate.HypertreeWidth(r, 2, 6, 3), 2},
+	}
+
+	for _, test := range tests {
+		graph, _ := lib.GetGraph(test.graph.HyperBench())
+
+		det := &algo.DetKDecomp{K: test.width, Graph: graph, BalFactor: 2}
+		decomp := det.FindDecomp()
+		if !decomp.Correct(graph) || decomp.CheckWidth() > test.width {
+			t.Errorf("No decomposition of width %v for %v", test.width, graph)
+		}
+
+		det = &algo.DetKDecomp{K: test.width - 1, Graph: graph, BalFactor: 2}
+		if test.width > 1 && !reflect.DeepEqual(det.FindDecomp(), lib.Decomp{}) {
+			t.Errorf("Found decomposition of width %v for %v", test.width-1, graph)
+		}
+	}
+}
+
+// TestGenerateInvalid makes sure that the generators produce empty hypergraphs for invalid parameters, instead of
+// empty edges, self-loops or panics
+func TestGenerateInvalid(t *testing.T) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	tests := map[string]generate.Hypergraph{
+		"uniform without vertices":  generate.Uniform(r, 0, 5, 3),
+		"uniform without arity":     generate.Uniform(r, 5, 5, 0),
+		"powerlaw without vertices": generate.PowerLaw(r, -1, 5, 3, 2),
+		"powerlaw without arity":    generate.PowerLaw(r, 5, 5, 0, 2),
+		"cycle of 1 vertex":         generate.Cycle(1),
+		"cycle of 2 vertices":       generate.Cycle(2),
+		"cq without variables":      generate.CQ(r, 5, 0, 3),
+		"cq without arity":          generate.CQ(r, 5, 5, 0),
+	}
+
+	for name, h := range tests {
+		if len(h.Edges) != 0 {
+			t.Errorf("%v produced edges %v", name, h.Edges)
+		}
+	}
+}
@@ -8,10 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
-	"github.com/cem-okulmus/disjoint"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -24,7 +22,7 @@ func check(e error) {
 
 // TestIntHash provides a basic test for hashes of integers
 func TestIntHash(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	for x := 0; x < 1000; x++ {
@@ -101,12 +99,12 @@ func BenchmarkSeparator(b *testing.B) {
 		return
 	}
 
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	parsedGraph, _ := lib.GetGraph(buf.String())
 	pred := lib.BalancedCheck{}
-	var Vertices = make(map[int]*disjoint.Element)
+	var ws lib.CompWorkspace
 
 	for i := 0; i < b.N; i++ {
 		var edges []int
@@ -117,13 +115,13 @@ func BenchmarkSeparator(b *testing.B) {
 		}
 
 		sep := lib.GetSubset(parsedGraph.Edges, edges)
-		pred.Check(&parsedGraph, &sep, 1, Vertices)
+		pred.Check(&parsedGraph, &sep, 1, &ws)
 	}
 }
 
 // TestEdgeHash tests the hash function of Edge against collisions and stability under permutation
 func TestEdgeHash(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	for x := 0; x < 100; x++ {
@@ -195,7 +193,7 @@ func TestEdgeHash(t *testing.T) {
 
 // TestEdgesHash tests the hash function of Edges against collisions and stability under permutation
 func TestEdgesHash(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	for x := 0; x < 100; x++ {
@@ -283,7 +281,7 @@ func TestEdgesHash(t *testing.T) {
 
 // TestGraphHash tests the hash function of Graph against stability under permutation
 func TestGraphHash1(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	var Sp []lib.Edges
@@ -356,7 +354,7 @@ func TestGraphHash1(t *testing.T) {
 
 //TestGraphTest2 is a Collision Test for hashes of Graphs
 func TestGraphHash2(t *testing.T) {
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	var Sp []lib.Edges
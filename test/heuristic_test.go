@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/lib/generate"
+)
+
+// countingCheck counts how many separators are checked for balancedness
+type countingCheck struct {
+	count *int
+}
+
+func (c countingCheck) Check(H *lib.Graph, sep *lib.Edges, balFactor int, ws *lib.CompWorkspace) bool {
+	*c.count++
+	return lib.BalancedCheck{}.Check(H, sep, balFactor, ws)
+}
+
+// searchCount returns the number of separators checked by a sequential search, until a balanced separator of a
+// single edge is found
+func searchCount(graph lib.Graph) int {
+	count := 0
+	edges := graph.Edges
+	generators := lib.SplitCombin(edges.Len(), 1, 1, false)
+	search := lib.ParallelSearchGen{}.GetSearch(&graph, &edges, 2, generators)
+	search.FindNext(countingCheck{count: &count})
+
+	return count
+}
+
+// sameEdges checks if two slices of edges are permutations of each other
+func sameEdges(a, b lib.Edges) bool {
+	names := func(e lib.Edges) []int {
+		var output []int
+		for _, edge := range e.Slice() {
+			output = append(output, edge.Name)
+		}
+		sort.Ints(output)
+		return output
+	}
+
+	namesA, namesB := names(a), names(b)
+	if len(namesA) != len(namesB) {
+		return false
+	}
+	for i := range namesA {
+		if namesA[i] != namesB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestEliminationOrders compares the number of separators checked with and without the elimination heuristics, on a
+// graph of triangles sharing the edge H, which is the only balanced separator of a single edge and comes last
+func TestEliminationOrders(t *testing.T) {
+	graph, _ := lib.GetGraph("A1(a,x1), B1(b,x1), A2(a,x2), B2(b,x2), A3(a,x3), B3(b,x3), A4(a,x4), B4(b,x4), " +
+		"A5(a,x5), B5(b,x5), H(a,b).")
+
+	unordered := searchCount(graph)
+	if unordered != graph.Edges.Len() {
+		t.Errorf("Expected %v checks without heuristic, got %v", graph.Edges.Len(), unordered)
+	}
+
+	heuristics := map[string]func(lib.Edges, int) lib.Edges{
+		"min-fill":   lib.GetMinFillOrder,
+		"min-degree": lib.GetMinDegreeOrder,
+		"MCS-M":      lib.GetMCSMOrder,
+	}
+
+	for name, heuristic := range heuristics {
+		for _, restarts := range []int{0, 5} {
+			ordered := lib.Graph{Edges: heuristic(graph.Edges, restarts)}
+
+			if !sameEdges(graph.Edges, ordered.Edges) {
+				t.Errorf("%v ordering %v is not a permutation of %v", name, ordered.Edges, graph.Edges)
+			}
+
+			count := searchCount(ordered)
+			if count >= unordered {
+				t.Errorf("%v ordering %v needs %v checks, not fewer than without heuristic", name, ordered.Edges,
+					count)
+			}
+			t.Logf("%v ordering with %v restarts needs %v checks", name, restarts, count)
+		}
+	}
+}
+
+// TestEliminationOrdersRandom checks that the elimination heuristics produce permutations of random graphs
+func TestEliminationOrdersRandom(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(15)
+
+		for _, heuristic := range []func(lib.Edges, int) lib.Edges{lib.GetMinFillOrder, lib.GetMinDegreeOrder,
+			lib.GetMCSMOrder} {
+			ordered := heuristic(graph.Edges, 2)
+			if !sameEdges(graph.Edges, ordered) {
+				t.Errorf("Ordering %v is not a permutation of %v", ordered, graph.Edges)
+			}
+		}
+	}
+}
+
+// benchmarkEliminationOrders runs each elimination heuristic once on the edges, without restarts
+func benchmarkEliminationOrders(b *testing.B, edges lib.Edges) {
+	heuristics := map[string]func(lib.Edges, int) lib.Edges{
+		"min-fill":   lib.GetMinFillOrder,
+		"min-degree": lib.GetMinDegreeOrder,
+		"MCS-M":      lib.GetMCSMOrder,
+	}
+
+	for name, heuristic := range heuristics {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				heuristic(edges, 0)
+			}
+		})
+	}
+}
+
+// BenchmarkEliminationOrders uses a grid of 3600 vertices, on which the elimination orderings produce a lot of fill-in
+func BenchmarkEliminationOrders(b *testing.B) {
+	graph, _ := lib.GetGraph(generate.Grid(60, 60).HyperBench())
+	benchmarkEliminationOrders(b, graph.Edges)
+}
+
+// BenchmarkEliminationOrdersHyperBench uses a specific hypergraph
+func BenchmarkEliminationOrdersHyperBench(b *testing.B) {
+	resp, err := http.Get("http://hyperbench.dbai.tuwien.ac.at/download/hypergraph/655")
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	buf := new(strings.Builder)
+	_, err = io.Copy(buf, resp.Body)
+	if err != nil {
+		return
+	}
+
+	parsedGraph, _ := lib.GetGraph(buf.String())
+	benchmarkEliminationOrders(b, parsedGraph.Edges)
+}
+
+// floydDistances computes all shortest paths in the primal graph of the edges, -1 marking unreachable pairs
+func floydDistances(index map[int]int, edges []lib.Edge) [][]int {
+	dist := make([][]int, len(index))
+	for i := range dist {
+		dist[i] = make([]int, len(index))
+		for j := range dist[i] {
+			dist[i][j] = -1
+		}
+		dist[i][i] = 0
+	}
+	for _, e := range edges {
+		for _, v := range e.Vertices {
+			for _, w := range e.Vertices {
+				if v != w {
+					dist[index[v]][index[w]] = 1
+				}
+			}
+		}
+	}
+
+	for k := range dist {
+		for i := range dist {
+			for j := range dist {
+				if dist[i][k] == -1 || dist[k][j] == -1 {
+					continue
+				}
+				if dist[i][j] == -1 || dist[i][k]+dist[k][j] < dist[i][j] {
+					dist[i][j] = dist[i][k] + dist[k][j]
+				}
+			}
+		}
+	}
+
+	return dist
+}
+
+// maxSepReference orders the edges as GetMaxSepOrder, using a separate Floyd-Warshall run for each edge
+func maxSepReference(edges lib.Edges) lib.Edges {
+	index := make(map[int]int)
+	for i, v := range edges.Vertices() {
+		index[v] = i
+	}
+	old := floydDistances(index, edges.Slice())
+
+	weights := make([]int, edges.Len())
+	for i, e := range edges.Slice() {
+		var without []lib.Edge
+		for _, other := range edges.Slice() {
+			if other.Hash() != e.Hash() {
+				without = append(without, other)
+			}
+		}
+		inEdge := make(map[int]bool)
+		for _, v := range e.Vertices {
+			inEdge[index[v]] = true
+		}
+
+		new := floydDistances(index, without)
+		for u := range new {
+			for v := range new[u] {
+				if u == v || (inEdge[u] && inEdge[v]) || new[u][v] == -1 {
+					continue
+				}
+				weights[i] = weights[i] + old[u][v] - new[u][v]
+			}
+		}
+	}
+
+	keys := make([]int, edges.Len())
+	for i := range keys {
+		keys[i] = i
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return weights[keys[i]] > weights[keys[j]] })
+
+	var output []lib.Edge
+	for _, k := range keys {
+		output = append(output, edges.Slice()[k])
+	}
+	return lib.NewEdges(output)
+}
+
+// TestMaxSepOrder compares the max. separator ordering against a direct implementation on random graphs
+func TestMaxSepOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(15)
+
+		expected := maxSepReference(graph.Edges)
+		ordered := lib.GetMaxSepOrder(graph.Edges)
+
+		if ordered.String() != expected.String() {
+			t.Errorf("Max. separator ordering %v differs from expected %v", ordered, expected)
+		}
+	}
+
+	// long paths, so that the graph has more vertices than are used as sources
+	var path []lib.Edge
+	for i := 0; i < 300; i++ {
+		path = append(path, lib.Edge{Name: i, Vertices: []int{i, i + 1}})
+	}
+	edges := lib.NewEdges(path)
+
+	ordered := lib.GetMaxSepOrder(edges)
+	if !sameEdges(edges, ordered) {
+		t.Errorf("Ordering of long path is not a permutation")
+	}
+}
+
+// TestMaxSepOrderTies checks on a cycle with a chord that the edges are sorted by decreasing weight, that edges of
+// equal weight keep their given order, and that the given edges are not modified
+func TestMaxSepOrderTies(t *testing.T) {
+	graph, _ := lib.GetGraph("E1(a,b), E2(b,c), E3(c,d), E4(d,e), E5(e,f), E6(f,a), C(a,d).")
+	given := graph.Edges.String()
+
+	// E2 and E5 lie in the middle of the two paths between a and d, followed by the chord and the remaining edges
+	expected := "{E2, E5, C, E1, E3, E4, E6}"
+	if ordered := lib.GetMaxSepOrder(graph.Edges); ordered.String() != expected {
+		t.Errorf("Max. separator ordering %v differs from expected %v", ordered, expected)
+	}
+	if graph.Edges.String() != given {
+		t.Errorf("Max. separator ordering modified the given edges from %v to %v", given, graph.Edges)
+	}
+}
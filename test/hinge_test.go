@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestDecompHingeStats checks the stats of the hinges, for a graph consisting of two cycles connected by one edge
+func TestDecompHingeStats(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,d), U(d,a), V(a,c,x), W(x,y), X(y,z), Y(z,a).")
+	hinget := lib.GetHingeTree(graph)
+
+	solver := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+
+	decomp, stats := hinget.DecompHingeStats(solver, graph)
+	if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+		t.Errorf("Incorrect decomposition from hingetree: %v", decomp)
+	}
+	if len(stats) < 2 {
+		t.Errorf("Expected at least 2 hinges, got %v", len(stats))
+	}
+	for i, stat := range stats {
+		if !stat.Solved || stat.Width > 2 || stat.Width < 1 {
+			t.Errorf("Unexpected stats for hinge %v: %v", i, stat)
+		}
+	}
+
+	// the cycles cannot be decomposed with width 1
+	solver.SetWidth(1)
+	decomp, stats = hinget.DecompHingeStats(solver, graph)
+	if !reflect.DeepEqual(decomp, lib.Decomp{}) {
+		t.Errorf("Expected rejection for width 1, got %v", decomp)
+	}
+
+	rejected := false
+	for _, stat := range stats {
+		rejected = rejected || !stat.Solved
+	}
+	if !rejected {
+		t.Error("No hinge recorded as rejected")
+	}
+}
+
+// TestDecompHingeCopies checks that solvers keeping state between runs can be reused for the hinges, alternating
+// between rejections and decompositions
+func TestDecompHingeCopies(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,d), U(d,a), V(a,c,x), W(x,y), X(y,z), Y(z,a), Z(z,u), Q(u,v).")
+	hinget := lib.GetHingeTree(graph)
+
+	det := &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}
+	global := &algo.BalSepGlobal{K: 1, Graph: graph, BalFactor: 2}
+	global.SetGenerator(lib.DeterministicSearchGen{})
+	portfolio := &algo.Portfolio{K: 1, Graph: graph, Members: []algo.PortfolioMember{
+		{Algorithm: &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}, Complete: true},
+		{Algorithm: &algo.BalSepGlobal{K: 1, Graph: graph, BalFactor: 2}, Complete: true},
+	}}
+
+	for _, solver := range []algo.Algorithm{det, global, portfolio} {
+		for i := 0; i < 3; i++ {
+			solver.SetWidth(1)
+			if decomp := hinget.DecompHinge(solver, graph); !reflect.DeepEqual(decomp, lib.Decomp{}) {
+				t.Errorf("%v: expected rejection for width 1, got %v", solver.Name(), decomp)
+			}
+
+			solver.SetWidth(2)
+			decomp := hinget.DecompHinge(solver, graph)
+			if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+				t.Errorf("%v: incorrect decomposition from hingetree: %v", solver.Name(), decomp)
+			}
+		}
+	}
+}
+
+// TestHingePortfolio checks the choice of algorithms for hinges of different sizes
+func TestHingePortfolio(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,d), U(d,a), V(a,c,x), W(x,y), X(y,z), Y(z,a), Z(z,u), Q(u,v).")
+
+	portfolio := &algo.HingePortfolio{K: 2, Graph: graph, BalFactor: 2, Threshold: 3}
+	decomp := portfolio.FindDecomp()
+
+	if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+		t.Errorf("Incorrect decomposition from hinge portfolio: %v", decomp)
+	}
+
+	chosen := make(map[string]bool)
+	for _, stat := range portfolio.Stats {
+		chosen[stat.Algorithm] = true
+		if stat.Algorithm == (&algo.AcyclicDecomp{}).Name() && stat.Width != 1 {
+			t.Errorf("Acyclic hinge with width %v", stat.Width)
+		}
+	}
+	if len(chosen) < 3 {
+		t.Errorf("Expected all three algorithms to be used, got %v", portfolio.Stats)
+	}
+}
+
+// TestAcyclicDecomp checks that join trees are found exactly for acyclic graphs
+func TestAcyclicDecomp(t *testing.T) {
+	acyclic, _ := lib.GetGraph("R(a,b,c), S(c,d), T(d,e,f), U(f,g), V(a,b,h).")
+	cyclic, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a).")
+
+	solver := &algo.AcyclicDecomp{}
+
+	decomp := solver.FindDecompGraph(acyclic)
+	if !decomp.Correct(acyclic) || decomp.CheckWidth() != 1 {
+		t.Errorf("Incorrect join tree: %v", decomp)
+	}
+
+	if !reflect.DeepEqual(solver.FindDecompGraph(cyclic), lib.Decomp{}) {
+		t.Error("Join tree found for cyclic graph")
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestCancelSearch checks that cancelled searches end without finding anything
+func TestCancelSearch(t *testing.T) {
+	graph, _ := getRandomGraph(10)
+	cancel := make(chan struct{})
+	close(cancel)
+
+	edges := graph.Edges
+	generators := lib.SplitCombin(edges.Len(), 2, 4, false)
+	search := lib.CancelSearchGen{Cancel: cancel}.GetSearch(&graph, &edges, 2, generators)
+
+	search.FindNext(lib.BalancedCheck{})
+	if !search.SearchEnded() {
+		t.Errorf("Cancelled search found %v", search.GetResult())
+	}
+
+	det := &algo.DetKDecomp{K: graph.Edges.Len(), Graph: graph, BalFactor: 2}
+	det.SetGenerator(lib.CancelSearchGen{Cancel: cancel})
+	if graph.Edges.Len() > 0 && !reflect.DeepEqual(det.FindDecomp(), lib.Decomp{}) {
+		t.Error("Cancelled DetK found a decomposition")
+	}
+}
+
+// TestPortfolio compares the portfolio with BalSep Local on random graphs
+func TestPortfolio(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		graph, _ := getRandomGraph(8)
+
+		for k := 2; k <= 3; k++ {
+			local := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}
+			local.SetGenerator(lib.ParallelSearchGen{})
+			direct := local.FindDecomp()
+
+			portfolio := &algo.Portfolio{K: k, Graph: graph, Members: []algo.PortfolioMember{
+				{Algorithm: &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}, Complete: true},
+				{Algorithm: &algo.DetKDecomp{K: k, Graph: graph, BalFactor: 2}},
+				{Algorithm: &algo.BalSepHybrid{K: k, Graph: graph, BalFactor: 2, Depth: 1}},
+			}}
+			decomp := portfolio.FindDecomp()
+
+			if portfolio.Winner == "" {
+				t.Errorf("Portfolio could not decide width %v for %v", k, graph)
+			}
+			if reflect.DeepEqual(direct, lib.Decomp{}) != reflect.DeepEqual(decomp, lib.Decomp{}) {
+				t.Errorf("Portfolio (won by %v) disagrees on existence of decomposition of width %v for %v",
+					portfolio.Winner, k, graph)
+			}
+			if !reflect.DeepEqual(decomp, lib.Decomp{}) && (!decomp.Correct(graph) || decomp.CheckWidth() > k) {
+				t.Errorf("Incorrect decomposition from portfolio: %v", decomp)
+			}
+		}
+	}
+}
+
+// workerRecorder wraps an algorithm, recording the number of goroutines its searches are split into
+type workerRecorder struct {
+	algo.Algorithm
+	workers *int
+}
+
+func (w workerRecorder) SetGenerator(Gen lib.SearchGenerator) {
+	*w.workers = lib.Workers(Gen)
+	w.Algorithm.SetGenerator(Gen)
+}
+
+// TestPortfolioWorkers checks that the goroutines given to a portfolio are divided among its members
+func TestPortfolioWorkers(t *testing.T) {
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(c,a).")
+
+	for _, c := range []struct {
+		workers  int
+		expected []int
+	}{
+		{7, []int{3, 2, 2}},
+		{2, []int{1, 1, 1}},
+	} {
+		workers := make([]int, len(c.expected))
+		var members []algo.PortfolioMember
+		for i := range workers {
+			members = append(members, algo.PortfolioMember{Algorithm: workerRecorder{
+				Algorithm: &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2}, workers: &workers[i]}})
+		}
+
+		portfolio := &algo.Portfolio{K: 2, Graph: graph, Members: members}
+		portfolio.SetGenerator(lib.CancelSearchGen{Workers: c.workers})
+		portfolio.FindDecomp()
+
+		if !reflect.DeepEqual(workers, c.expected) {
+			t.Errorf("Members of portfolio with %v goroutines got %v, expected %v", c.workers, workers, c.expected)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// typeOf describes a vertex by the positions of the edges containing it
+func typeOf(graph lib.Graph, v int) string {
+	var positions []string
+	for i, e := range graph.Edges.Slice() {
+		for _, w := range e.Vertices {
+			if v == w {
+				positions = append(positions, fmt.Sprint(i))
+				break
+			}
+		}
+	}
+	return strings.Join(positions, ",")
+}
+
+// TestTypeCollapse compares the type collapse against the types computed directly, and checks that decompositions
+// of the collapsed graph can be restored
+func TestTypeCollapse(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(20)
+
+		collapsed, restoreMap, count := graph.TypeCollapse()
+
+		// each type must be kept exactly once, by the first vertex of that type
+		kept := make(map[string]int)
+		removed := 0
+		for _, v := range graph.Vertices() {
+			typ := typeOf(graph, v)
+			if rep, ok := kept[typ]; ok {
+				removed++
+				found := false
+				for _, w := range restoreMap[rep] {
+					found = found || w == v
+				}
+				if !found {
+					t.Errorf("Vertex %v not restored by %v of the same type", v, rep)
+				}
+			} else {
+				kept[typ] = v
+			}
+		}
+		if removed != count || len(collapsed.Vertices()) != len(kept) {
+			t.Errorf("Expected %v types and %v removed vertices, got %v and %v", len(kept), removed,
+				len(collapsed.Vertices()), count)
+		}
+
+		decomp := lib.Decomp{Graph: collapsed, Root: lib.Node{Bag: collapsed.Vertices(), Cover: collapsed.Edges}}
+		root, ok := decomp.Root.RestoreTypes(restoreMap)
+		if !ok {
+			t.Errorf("Could not restore types of %v", graph)
+			continue
+		}
+		restored := lib.Decomp{Graph: graph, Root: root}
+		if !restored.Correct(graph) {
+			t.Errorf("Restored decomposition %v not correct for %v", restored, graph)
+		}
+	}
+}
+
+// BenchmarkTypeCollapse uses a large random hypergraph with many vertices of the same type
+func BenchmarkTypeCollapse(b *testing.B) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	var edges []lib.Edge
+	for i := 0; i < 2000; i++ {
+		var vertices []int
+		for j := 0; j < 10; j++ {
+			vertices = append(vertices, r.Intn(5000))
+		}
+		edges = append(edges, lib.Edge{Name: i, Vertices: lib.RemoveDuplicates(vertices)})
+	}
+	graph := lib.Graph{Edges: lib.NewEdges(edges)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.TypeCollapse()
+	}
+}
+
+// BenchmarkTypeCollapseHyperBench uses a specific hypergraph
+func BenchmarkTypeCollapseHyperBench(b *testing.B) {
+	// Get the data
+	resp, err := http.Get("http://hyperbench.dbai.tuwien.ac.at/download/hypergraph/655")
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	buf := new(strings.Builder)
+	_, err = io.Copy(buf, resp.Body)
+	if err != nil {
+		return
+	}
+
+	parsedGraph, _ := lib.GetGraph(buf.String())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parsedGraph.TypeCollapse()
+	}
+}
+
+// TestGYÖReduct checks the operations of the GYÖ reduction on fixed graphs, one cyclic and one acyclic
+func TestGYÖReduct(t *testing.T) {
+	tests := []struct {
+		graph   string
+		reduced string
+		ops     string
+		acyclic bool
+	}{
+		{
+			graph:   "E1(a,b,c), E2(b,c), E3(c,d,e), E4(e,f), E5(d,e), E6(f,a,g).",
+			reduced: "{E1, E3, E4, E6}",
+			ops:     "[(g ∈ E6) (d ∈ E3) (b ∈ E1) (E5 ⊆ E3) (E2 ⊆ E1)]",
+			acyclic: false,
+		},
+		{
+			graph:   "E1(a,b,c), E2(b,c), E3(c,d), E4(d,e,f), E5(f,g).",
+			reduced: "{}",
+			ops: "[(d ∈ E4) (E3 ⊆ E4) (f ∈ E4) (c ∈ E3) (E5 ⊆ E4) (E1 ⊆ E3) (g ∈ E5) (e ∈ E4) (b ∈ E1) (a ∈ E1) " +
+				"(E2 ⊆ E1)]",
+			acyclic: true,
+		},
+	}
+
+	for _, test := range tests {
+		graph, _ := lib.GetGraph(test.graph)
+		reduced, ops := graph.GYÖReduct()
+
+		if reduced.String() != test.reduced || fmt.Sprint(ops) != test.ops {
+			t.Errorf("GYÖ reduction of %v: expected %v with %v, got %v with %v", graph, test.reduced, test.ops,
+				reduced, ops)
+		}
+		if graph.IsAlphaAcyclic() != test.acyclic {
+			t.Errorf("Expected acyclicity of %v to be %v", graph, test.acyclic)
+		}
+	}
+}
+
+// TestGYÖRestore checks that decompositions of GYÖ reduced random graphs can be restored
+func TestGYÖRestore(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		graph, _ := getRandomGraph(20)
+		reduced, ops := graph.GYÖReduct()
+
+		var root lib.Node
+		if reduced.Edges.Len() > 0 {
+			root = lib.Node{Bag: reduced.Vertices(), Cover: reduced.Edges}
+		}
+		restored, ok := root.RestoreGYÖ(ops)
+		if !ok {
+			t.Errorf("Could not restore GYÖ reduction of %v", graph)
+			continue
+		}
+
+		decomp := lib.Decomp{Graph: graph, Root: restored}
+		if !decomp.Correct(graph) {
+			t.Errorf("Restored decomposition %v not correct for %v", decomp, graph)
+		}
+	}
+}
+
+// BenchmarkGYÖReduct uses a long chain of edges and subedges, which is reduced over many rounds
+func BenchmarkGYÖReduct(b *testing.B) {
+	var edges []lib.Edge
+	for i := 0; i < 2000; i++ {
+		edges = append(edges, lib.Edge{Name: 2 * i, Vertices: []int{i, i + 1, i + 2}})
+		edges = append(edges, lib.Edge{Name: 2*i + 1, Vertices: []int{i + 1, i + 2}})
+	}
+	graph := lib.Graph{Edges: lib.NewEdges(edges)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.GYÖReduct()
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/lib/generate"
+)
+
+// TestCombinationCount compares the size of the search space against the combinations actually produced
+func TestCombinationCount(t *testing.T) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	for x := 0; x < 20; x++ {
+		n := r.Intn(15) + 1
+		k := r.Intn(5) + 1
+		unextended := r.Intn(2) == 0
+
+		count := 0
+		for _, combin := range lib.SplitCombin(n, k, 1, unextended) {
+			for combin.HasNext() {
+				combin.GetNext()
+				count++
+				combin.Confirm()
+			}
+		}
+
+		if expected := lib.CombinationCount(n, k, unextended); float64(count) != expected {
+			t.Errorf("n %v, k %v, unextended %v: counted %v combinations, expected %v", n, k, unextended, count,
+				expected)
+		}
+	}
+}
+
+// TestCounters checks that the counters set on an algorithm are updated during the search
+func TestCounters(t *testing.T) {
+	graph, _ := lib.GetGraph(generate.Cycle(8).HyperBench())
+
+	// no single edge is a balanced separator of a cycle, so the entire top-level search space is checked
+	var counters algo.Counters
+	counters.Init()
+	local := &algo.BalSepLocal{K: 1, Graph: graph, BalFactor: 2}
+	local.SetGenerator(lib.DeterministicSearchGen{})
+	local.SetCounters(&counters)
+
+	if !reflect.DeepEqual(local.FindDecomp(), lib.Decomp{}) {
+		t.Fatal("Found decomposition of width 1 for a cycle")
+	}
+	copied := local.GetCounters()
+	if p := copied.Progress(); p.Completion != 1 || p.Separators != int64(graph.Edges.Len()) || p.Balanced != 0 {
+		t.Errorf("Counters of the exhausted search are wrong: %+v", p)
+	}
+
+	// DetKDecomp has to backtrack and use its cache to reject the cycle
+	counters = algo.Counters{}
+	counters.Init()
+	det := &algo.DetKDecomp{K: 1, Graph: graph, BalFactor: 2}
+	det.SetCounters(&counters)
+
+	if !reflect.DeepEqual(det.FindDecomp(), lib.Decomp{}) {
+		t.Fatal("Found decomposition of width 1 for a cycle")
+	}
+	p := counters.Progress()
+	if p.Completion != -1 || len(p.Backtracks) == 0 || p.CacheLookups == 0 || p.CacheEntries == 0 ||
+		p.Separators == 0 {
+		t.Errorf("Counters of DetKDecomp not updated: %+v", p)
+	}
+	if p.CacheSize != 0 {
+		t.Errorf("Cache of a finished search still counted: %+v", p)
+	}
+
+	// algorithms without counters must not be affected
+	det = &algo.DetKDecomp{K: 2, Graph: graph, BalFactor: 2}
+	if decomp := det.FindDecomp(); !decomp.Correct(graph) {
+		t.Error("No decomposition of width 2 found without counters")
+	}
+}
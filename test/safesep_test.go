@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// TestSplitSafeSeps checks the splits of two cycles connected via the edge V, which also covers a chord of the first
+func TestSplitSafeSeps(t *testing.T) {
+	graph, pGraph := lib.GetGraph("R(a,b), S(b,c), T(c,d), U(d,a), V(a,c,x), W(x,y), X(y,z), Y(z,a).")
+	a, c, x := pGraph.Encoding["a"], pGraph.Encoding["c"], pGraph.Encoding["x"]
+
+	// the clique minimal separators are {a,c} and {a,x}, which produce the atoms {a,b,c}, {a,c,d}, {a,c,x} and
+	// {a,x,y,z}
+	safeSeps := lib.SplitSafeSeps(graph)
+	if safeSeps.Len() != 4 {
+		t.Errorf("Expected 4 parts, got %v: %v", safeSeps.Len(), safeSeps.Parts)
+	}
+	for _, op := range safeSeps.Ops {
+		if !(lib.Subset(op.Sep, []int{a, c}) && len(op.Sep) == 2) && !(lib.Subset(op.Sep, []int{a, x}) &&
+			len(op.Sep) == 2) {
+			t.Errorf("Unexpected separator %v", lib.PrintVertices(op.Sep))
+		}
+	}
+
+	names := make(map[int]int)
+	for _, p := range safeSeps.Parts {
+		for _, e := range p.Edges.Slice() {
+			names[e.Name]++
+		}
+	}
+	for _, e := range graph.Edges.Slice() {
+		if names[e.Name] != 1 {
+			t.Errorf("Edge %v contained in %v parts", e, names[e.Name])
+		}
+	}
+
+	solver := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	decomp := safeSeps.DecompSafeSeps(solver, graph, 2)
+
+	if !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+		t.Errorf("Incorrect decomposition from safe separators: %v", decomp)
+	}
+}
+
+// TestSafeSepCover checks that a separator which cannot be covered by k edges rules out a decomposition of width k
+func TestSafeSepCover(t *testing.T) {
+	// {a,b,c} is a clique separator covered by two edges, splitting off the cycle at d
+	graph, _ := lib.GetGraph("R(a,b), S(b,c), T(a,c), U(a,d), V(d,b), W(c,e), X(e,f), Y(f,a).")
+
+	safeSeps := lib.SplitSafeSeps(graph)
+	if safeSeps.Len() < 2 {
+		t.Fatalf("Expected at least 2 parts, got %v: %v", safeSeps.Len(), safeSeps.Parts)
+	}
+
+	solver := &algo.BalSepLocal{K: 1, Graph: graph, BalFactor: 2}
+	solver.SetGenerator(lib.ParallelSearchGen{})
+	if decomp := safeSeps.DecompSafeSeps(solver, graph, 1); !reflect.DeepEqual(decomp, lib.Decomp{}) {
+		t.Errorf("Found decomposition of width 1: %v", decomp)
+	}
+
+	solver.SetWidth(2)
+	if decomp := safeSeps.DecompSafeSeps(solver, graph, 2); !decomp.Correct(graph) || decomp.CheckWidth() > 2 {
+		t.Errorf("Incorrect decomposition from safe separators: %v", decomp)
+	}
+}
+
+// TestDecompSafeSeps compares decomposing via safe separators with decomposing the entire graph on random graphs
+func TestDecompSafeSeps(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		graph, _ := getRandomGraph(8)
+
+		safeSeps := lib.SplitSafeSeps(graph)
+
+		for k := 2; k <= 3; k++ { // the base cases of BalSep assume a width of at least 2
+			solver := &algo.BalSepLocal{K: k, Graph: graph, BalFactor: 2}
+			solver.SetGenerator(lib.ParallelSearchGen{})
+
+			direct := solver.FindDecomp()
+			viaSafeSeps := safeSeps.DecompSafeSeps(solver, graph, k)
+
+			if reflect.DeepEqual(direct, lib.Decomp{}) != reflect.DeepEqual(viaSafeSeps, lib.Decomp{}) {
+				t.Errorf("Safe separators disagree on existence of decomposition of width %v for %v", k, graph)
+			}
+			if !reflect.DeepEqual(viaSafeSeps, lib.Decomp{}) &&
+				(!viaSafeSeps.Correct(graph) || viaSafeSeps.CheckWidth() > k) {
+				t.Errorf("Incorrect decomposition from safe separators: %v", viaSafeSeps)
+			}
+		}
+	}
+}
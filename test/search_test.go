@@ -5,7 +5,6 @@ import (
 	"math/rand"
 	"runtime"
 	"testing"
-	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
@@ -24,7 +23,7 @@ func max(a, b int) int {
 // no matter how many splits are generated and run in parallel
 func TestSearchBal(t *testing.T) {
 
-	s := rand.NewSource(time.Now().UnixNano())
+	s := rand.NewSource(nextSeed())
 	r := rand.New(s)
 
 	randGraph, _ := getRandomGraph(20)
@@ -119,3 +118,43 @@ OUTER:
 		t.Errorf("Mismatch in returned seps between sequential and parallel Search")
 	}
 }
+
+// TestSearchDeterministic ensures that the deterministic search returns the same separators in the same order as a
+// sequential search, no matter how many splits are generated
+func TestSearchDeterministic(t *testing.T) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	randGraph, _ := getRandomGraph(15)
+	k := r.Intn(3) + 1
+	pred := lib.BalancedCheck{}
+
+	allSeps := func(search lib.Search) []uint64 {
+		var output []uint64
+		for search.FindNext(pred); !search.SearchEnded(); search.FindNext(pred) {
+			sep := lib.GetSubset(randGraph.Edges, search.GetResult())
+			output = append(output, sep.Hash())
+		}
+		return output
+	}
+
+	seq := allSeps(lib.ParallelSearchGen{}.GetSearch(&randGraph, &randGraph.Edges, 2,
+		lib.SplitCombin(randGraph.Edges.Len(), k, 1, false)))
+
+	for _, split := range []int{2, 3, max(4, runtime.GOMAXPROCS(-1))} {
+		det := allSeps(lib.DeterministicSearchGen{}.GetSearch(&randGraph, &randGraph.Edges, 2,
+			lib.SplitCombin(randGraph.Edges.Len(), k, split, false)))
+
+		if len(det) != len(seq) {
+			t.Errorf("Deterministic search with %v splits found %v separators, sequential search %v", split,
+				len(det), len(seq))
+			continue
+		}
+		for i := range seq {
+			if seq[i] != det[i] {
+				t.Errorf("Deterministic search with %v splits differs from sequential search at separator %v", split,
+					i)
+				break
+			}
+		}
+	}
+}
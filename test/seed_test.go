@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// seed makes the randomized tests reproducible, e.g. go test ./test -args -seed=42
+var seed = flag.Int64("seed", 0, "seed for the randomized tests, taken from the clock if zero")
+
+var (
+	seedOnce sync.Once
+	seedMux  sync.Mutex
+	seedRand *rand.Rand
+)
+
+// nextSeed returns a seed for a single randomized test, derived from the seed of the test run
+func nextSeed() int64 {
+	seedOnce.Do(func() {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		log.Println("Seed of the test run:", *seed)
+		seedRand = rand.New(rand.NewSource(*seed))
+		lib.SetSeed(*seed)
+	})
+
+	seedMux.Lock()
+	defer seedMux.Unlock()
+
+	return seedRand.Int63()
+}
+
+// TestSeedReproducible checks that the same seed leads to the same orderings and decompositions
+func TestSeedReproducible(t *testing.T) {
+	graph, _ := getRandomGraph(8)
+	s := nextSeed()
+
+	run := func() (lib.Edges, string) {
+		lib.SetSeed(s)
+		order := lib.GetMSCOrder(graph.Edges)
+
+		local := &algo.BalSepLocal{K: 2, Graph: lib.Graph{Edges: order}, BalFactor: 2}
+		local.SetGenerator(lib.DeterministicSearchGen{})
+		return order, local.FindDecomp().String()
+	}
+
+	order, decomp := run()
+	for i := 0; i < 3; i++ {
+		otherOrder, otherDecomp := run()
+		if otherOrder.String() != order.String() {
+			t.Errorf("MSC ordering not reproducible with seed %v: %v vs %v", s, order, otherOrder)
+		}
+		if otherDecomp != decomp {
+			t.Errorf("Decomposition not reproducible with seed %v:\n%v\nvs\n%v", s, decomp, otherDecomp)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	algo "github.com/cem-okulmus/BalancedGo/algorithms"
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/BalancedGo/lib/generate"
+)
+
+// TestTrace records the search of BalSepLocal and reads the trace back
+func TestTrace(t *testing.T) {
+	graph, _ := lib.GetGraph(generate.Cycle(8).HyperBench())
+
+	var buf bytes.Buffer
+	tracer := lib.NewTracer(&buf)
+	local := &algo.BalSepLocal{K: 2, Graph: graph, BalFactor: 2, Tracer: tracer}
+	local.SetGenerator(lib.DeterministicSearchGen{})
+
+	decomp := local.FindDecomp()
+	if !decomp.Correct(graph) {
+		t.Fatal("Decomposition not correct while tracing")
+	}
+	if err := tracer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := lib.ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entered := make(map[int64]lib.TraceEvent)
+	exited := 0
+	for _, e := range events {
+		switch e.Kind {
+		case lib.TraceEnter:
+			entered[e.Step] = e
+		case lib.TraceExit:
+			if _, ok := entered[e.Step]; !ok {
+				t.Fatalf("Step %v exited without entering", e.Step)
+			}
+			exited++
+			if e.Step == 1 && !e.Accepted {
+				t.Error("Top-level step not accepted")
+			}
+		}
+	}
+	if len(entered) == 0 || exited != len(entered) {
+		t.Errorf("Entered %v steps, but exited %v", len(entered), exited)
+	}
+	if top := entered[1]; top.Parent != 0 || top.Depth != 1 || top.Graph != graph.Hash() {
+		t.Errorf("Top-level step wrong: %+v", top)
+	}
+}
+
+// TestTraceLongLine reads back a trace with a separator too long for the default line length of a bufio.Scanner
+func TestTraceLongLine(t *testing.T) {
+	var edges []lib.Edge
+	for i := 1; i <= 20000; i++ {
+		edges = append(edges, lib.Edge{Name: 10000 + i, Vertices: []int{i}})
+	}
+
+	var buf bytes.Buffer
+	tracer := lib.NewTracer(&buf)
+	tracer.Separator(1, lib.NewEdges(edges), false, nil)
+	tracer.Close()
+
+	if buf.Len() <= 64*1024 {
+		t.Fatalf("Trace of %v bytes is not longer than the default line length", buf.Len())
+	}
+
+	events, err := lib.ReadTrace(&buf)
+	if err != nil {
+		t.Fatal("Can't read trace with a long line:", err)
+	}
+	if len(events) != 1 || len(events[0].Separator) != len(edges) {
+		t.Errorf("Read %v events from trace with a single long line", len(events))
+	}
+}
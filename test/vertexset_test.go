@@ -0,0 +1,287 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// getRandomVertices produces a random slice of vertices, possibly containing duplicates
+func getRandomVertices(r *rand.Rand, size int, offset int) []int {
+	var output []int
+
+	for i := r.Intn(size); i > 0; i-- {
+		output = append(output, offset+r.Intn(size*8))
+	}
+
+	return output
+}
+
+// setOf is the reference implementation of a vertex set
+func setOf(vertices []int) map[int]bool {
+	output := make(map[int]bool)
+	for _, v := range vertices {
+		output[v] = true
+	}
+	return output
+}
+
+func sortedKeys(set map[int]bool) []int {
+	output := []int{}
+	for v := range set {
+		output = append(output, v)
+	}
+	sort.Ints(output)
+	return output
+}
+
+// TestVertexSet compares the operations on vertex sets against maps, for sets with different offsets
+func TestVertexSet(t *testing.T) {
+	r := rand.New(rand.NewSource(nextSeed()))
+
+	for i := 0; i < 1000; i++ {
+		as := getRandomVertices(r, 40, r.Intn(300))
+		bs := getRandomVertices(r, 40, r.Intn(300))
+		a, b := lib.NewVertexSet(as), lib.NewVertexSet(bs)
+		refA, refB := setOf(as), setOf(bs)
+
+		union, inter, diff := make(map[int]bool), make(map[int]bool), make(map[int]bool)
+		subset, intersects := true, false
+		for v := range refA {
+			union[v] = true
+			if refB[v] {
+				inter[v] = true
+				intersects = true
+			} else {
+				diff[v] = true
+				subset = false
+			}
+		}
+		for v := range refB {
+			union[v] = true
+		}
+
+		if out := a.Slice(); !reflect.DeepEqual(out, sortedKeys(refA)) {
+			t.Fatalf("Slice of %v: got %v", as, out)
+		}
+		if a.Len() != len(refA) || a.IsEmpty() != (len(refA) == 0) {
+			t.Fatalf("Len of %v: got %v", as, a.Len())
+		}
+		if out := a.Union(b).Slice(); !reflect.DeepEqual(out, sortedKeys(union)) {
+			t.Fatalf("Union of %v and %v: got %v", as, bs, out)
+		}
+		if out := a.Inter(b).Slice(); !reflect.DeepEqual(out, sortedKeys(inter)) {
+			t.Fatalf("Inter of %v and %v: got %v", as, bs, out)
+		}
+		if a.InterLen(b) != len(inter) {
+			t.Fatalf("InterLen of %v and %v: got %v", as, bs, a.InterLen(b))
+		}
+		if out := a.Diff(b).Slice(); !reflect.DeepEqual(out, sortedKeys(diff)) {
+			t.Fatalf("Diff of %v and %v: got %v", as, bs, out)
+		}
+		if a.SubsetOf(b) != subset || a.Intersects(b) != intersects {
+			t.Fatalf("Subset or Intersects of %v and %v wrong", as, bs)
+		}
+		if a.Equal(b) != (subset && len(refA) == len(refB)) {
+			t.Fatalf("Equal of %v and %v wrong", as, bs)
+		}
+
+		c := a.Clone()
+		c.UnionWith(b)
+		for _, v := range bs {
+			c.Remove(v)
+			c.Add(v)
+		}
+		if !c.Equal(a.Union(b)) || !reflect.DeepEqual(a.Slice(), sortedKeys(refA)) {
+			t.Fatalf("UnionWith, Add or Remove on %v and %v wrong", as, bs)
+		}
+
+		// the slice based operations must keep the order of their first argument
+		var expectedInter, expectedDiff []int
+		for _, v := range as {
+			if refB[v] {
+				expectedInter = append(expectedInter, v)
+			} else {
+				expectedDiff = append(expectedDiff, v)
+			}
+		}
+		if out := lib.Inter(as, bs); len(out) != len(expectedInter) ||
+			(len(out) > 0 && !reflect.DeepEqual(out, expectedInter)) {
+			t.Fatalf("lib.Inter of %v and %v: got %v", as, bs, out)
+		}
+		if out := lib.Diff(as, bs); len(out) != len(expectedDiff) ||
+			(len(out) > 0 && !reflect.DeepEqual(out, expectedDiff)) {
+			t.Fatalf("lib.Diff of %v and %v: got %v", as, bs, out)
+		}
+		if lib.Subset(as, bs) != subset {
+			t.Fatalf("lib.Subset of %v and %v wrong", as, bs)
+		}
+	}
+}
+
+// TestCorrectConnectedness makes sure that the correctness check rejects a decomposition in which a vertex does not
+// span a connected subtree
+func TestCorrectConnectedness(t *testing.T) {
+	graph, _ := lib.GetGraph("E1(a,b), E2(b,c), E3(c,d).")
+	e1, e2, e3 := graph.Edges.Slice()[0], graph.Edges.Slice()[1], graph.Edges.Slice()[2]
+
+	node := func(e lib.Edge, children ...lib.Node) lib.Node {
+		return lib.Node{Bag: e.Vertices, Cover: lib.NewEdges([]lib.Edge{e}), Children: children}
+	}
+
+	valid := lib.Decomp{Graph: graph, Root: node(e1, node(e2, node(e3)))}
+	if !valid.Correct(graph) {
+		t.Errorf("Valid decomposition rejected: %v", valid)
+	}
+
+	// b occurs in the root and its grandchild, but not in the child in between
+	invalid := lib.Decomp{Graph: graph, Root: node(e1, node(e3, node(e2)))}
+	if invalid.Correct(graph) {
+		t.Errorf("Disconnected decomposition accepted: %v", invalid)
+	}
+}
+
+// TestStoredSets checks that the sets stored in edges and nodes match their vertices, also for edges and nodes
+// produced by the library and for those built as literals
+func TestStoredSets(t *testing.T) {
+	graph, _ := getRandomGraph(10)
+
+	check := func(what string, set lib.VertexSet, vertices []int) {
+		if !reflect.DeepEqual(set.Slice(), sortedKeys(setOf(vertices))) {
+			t.Errorf("Set of %v is %v, expected %v", what, set.Slice(), sortedKeys(setOf(vertices)))
+		}
+	}
+
+	for _, e := range graph.Edges.Slice() {
+		check("parsed edge "+e.String(), e.Set(), e.Vertices)
+	}
+
+	vertices := graph.Edges.Vertices()[:len(graph.Edges.Vertices())/2]
+	for _, e := range lib.CutEdges(graph.Edges, vertices).Slice() {
+		check("cut edge "+e.FullString(), e.Set(), e.Vertices)
+	}
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(graph); err != nil {
+		t.Fatal("Can't encode graph:", err)
+	}
+	var decoded lib.Graph
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal("Can't decode graph:", err)
+	}
+	for _, e := range decoded.Edges.Slice() {
+		check("decoded edge "+e.String(), e.Set(), e.Vertices)
+	}
+
+	literal := lib.Edge{Name: 1, Vertices: []int{3, 1, 2, 3}}
+	check("literal edge", literal.Set(), literal.Vertices)
+
+	node := lib.NewNode(graph.Edges.Vertices(), graph.Edges)
+	check("node", node.BagSet(), node.Bag)
+	literalNode := lib.Node{Bag: []int{5, 4}}
+	check("literal node", literalNode.BagSet(), literalNode.Bag)
+}
+
+func BenchmarkSubset(b *testing.B) {
+	r := rand.New(rand.NewSource(nextSeed()))
+	as := getRandomVertices(r, 100, 0)
+	bs := append(getRandomVertices(r, 300, 0), as...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.Subset(as, bs)
+	}
+}
+
+// getTreeGraph produces a graph shaped like a binary tree, where the edge i shares the vertex i with each of its
+// children, along with a decomposition following the tree
+func getTreeGraph(n int) (lib.Graph, lib.Decomp) {
+	r := rand.New(rand.NewSource(nextSeed()))
+	var buffer bytes.Buffer
+	fresh := n
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buffer.WriteString(",\n")
+		}
+		buffer.WriteString("E" + strconv.Itoa(i) + "(V" + strconv.Itoa(i))
+		if i > 0 {
+			buffer.WriteString(",V" + strconv.Itoa((i-1)/2))
+		}
+		for j := r.Intn(4); j > 0; j-- {
+			buffer.WriteString(",V" + strconv.Itoa(fresh))
+			fresh++
+		}
+		buffer.WriteString(")")
+	}
+	buffer.WriteString(".")
+	graph, _ := lib.GetGraph(buffer.String())
+
+	var node func(i int) lib.Node
+	node = func(i int) lib.Node {
+		e := graph.Edges.Slice()[i]
+		output := lib.Node{Bag: e.Vertices, Cover: lib.NewEdges([]lib.Edge{e})}
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < n {
+				output.Children = append(output.Children, node(c))
+			}
+		}
+		return output
+	}
+
+	return graph, lib.Decomp{Graph: graph, Root: node(0)}
+}
+
+func BenchmarkFilterVertices(b *testing.B) {
+	graph, _ := getTreeGraph(2000)
+	vertices := graph.Edges.Vertices()
+	vertices = vertices[:len(vertices)/2]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.FilterVertices(graph.Edges, vertices)
+	}
+}
+
+func BenchmarkCutEdges(b *testing.B) {
+	graph, _ := getTreeGraph(2000)
+	vertices := graph.Edges.Vertices()
+	vertices = vertices[:len(vertices)/2]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.CutEdges(graph.Edges, vertices)
+	}
+}
+
+func BenchmarkMSCOrder(b *testing.B) {
+	graph, _ := getTreeGraph(300)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lib.GetMSCOrder(graph.Edges)
+	}
+}
+
+func BenchmarkCorrect(b *testing.B) {
+	graph, decomp := getTreeGraph(2000)
+	if !decomp.Correct(graph) {
+		b.Fatal("incorrect decomposition")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decomp.Correct(graph)
+	}
+}
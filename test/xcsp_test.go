@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+const xcspInstance = `
+<instance format="XCSP3" type="CSP">
+  <variables>
+    <var id="x"> 0..3 </var>
+    <var id="y"> 0..3 </var>
+    <array id="z" size="[2][3]"> 0..9 </array>
+  </variables>
+  <constraints>
+    <extension id="ext">
+      <list> x y </list>
+      <supports> (0,1)(1,2)(2,3) </supports>
+    </extension>
+    <intension id="int"> eq(add(x,z[0][1]),3) </intension>
+    <allDifferent id="alldiff"> z[1][] </allDifferent>
+    <block>
+      <sum id="sum">
+        <list> z[0..1][2] </list>
+        <coeffs> 1 2 </coeffs>
+        <condition> (le,y) </condition>
+      </sum>
+    </block>
+    <group id="grp">
+      <intension> ne(%0,%1) </intension>
+      <args> x z[0][0] </args>
+      <args> y z[0][0] </args>
+    </group>
+    <lex>
+      <list> z[0][] </list>
+    </lex>
+  </constraints>
+</instance>
+`
+
+// TestXCSP checks that the scopes of various kinds of XCSP3 constraints are extracted correctly
+func TestXCSP(t *testing.T) {
+	graph, pGraph := lib.GetGraphXCSP(xcspInstance)
+
+	expected := map[string][]string{
+		"ext":     {"x", "y"},
+		"int":     {"x", "z[0][1]"},
+		"alldiff": {"z[1][0]", "z[1][1]", "z[1][2]"},
+		"sum":     {"z[0][2]", "z[1][2]", "y"},
+		"grp[0]":  {"x", "z[0][0]"},
+		"grp[1]":  {"y", "z[0][0]"},
+		"c6":      {"z[0][0]", "z[0][1]", "z[0][2]"},
+	}
+
+	if graph.Edges.Len() != len(expected) {
+		t.Errorf("Expected %v edges, got %v: %v", len(expected), graph.Edges.Len(), graph)
+	}
+
+	for name, scope := range expected {
+		encoded, ok := pGraph.Encoding[name]
+		if !ok {
+			t.Errorf("No edge for constraint %v", name)
+			continue
+		}
+
+		var vertices []int
+		for _, v := range scope {
+			vertices = append(vertices, pGraph.Encoding[v])
+		}
+
+		found := false
+		for _, e := range graph.Edges.Slice() {
+			if e.Name != encoded {
+				continue
+			}
+			found = true
+			if !lib.Subset(e.Vertices, vertices) || !lib.Subset(vertices, e.Vertices) {
+				t.Errorf("Wrong scope for constraint %v: %v", name, e.FullString())
+			}
+		}
+		if !found {
+			t.Errorf("Edge for constraint %v missing from graph", name)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// This package implements a tool to benchmark BalancedGo over a directory of instances. It runs a matrix of
+// configurations (algorithm × heuristic × preprocessing × width mode) on each instance in a separate process with a
+// timeout, records the results as CSV or JSON, and compares two such result files to find regressions.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Config is one point of the benchmark matrix
+type Config struct {
+	Algorithm     string
+	Heuristic     int
+	Preprocessing string
+	Width         string
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("%v, heuristic %v, preprocessing %v, width %v", c.Algorithm, c.Heuristic, c.Preprocessing,
+		c.Width)
+}
+
+// algorithmFlags translates an algorithm of the matrix into flags of BalancedGo
+func algorithmFlags(algorithm string) ([]string, error) {
+	name, arg := algorithm, ""
+	if i := strings.Index(algorithm, ":"); i >= 0 {
+		name, arg = algorithm[:i], algorithm[i+1:]
+	}
+
+	switch name {
+	case "local", "global", "det", "auto":
+		if arg != "" {
+			return nil, fmt.Errorf("algorithm %v takes no argument", name)
+		}
+		return []string{"-" + name}, nil
+	case "localbip":
+		return []string{"-det", "-localbip"}, nil
+	case "balDet", "seqBalDet", "hingePortfolio":
+		if _, err := strconv.Atoi(arg); err != nil {
+			return nil, fmt.Errorf("algorithm %v needs a depth or threshold, as in %v:1", name, name)
+		}
+		return []string{"-" + name, arg}, nil
+	case "portfolio":
+		return []string{"-portfolio", strings.Replace(arg, "+", ",", -1)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown algorithm %v", algorithm)
+}
+
+// preprocessingFlags translates a combination of preprocessing steps, such as g+t, into flags of BalancedGo
+func preprocessingFlags(preprocessing string) ([]string, error) {
+	var output []string
+	if preprocessing == "none" {
+		return output, nil
+	}
+
+	for _, step := range strings.Split(preprocessing, "+") {
+		switch step {
+		case "g", "t", "h", "blocks", "safe":
+			output = append(output, "-"+step)
+		default:
+			return nil, fmt.Errorf("unknown preprocessing %v", step)
+		}
+	}
+
+	return output, nil
+}
+
+// widthFlags translates a width mode, i.e. exact, approx:<seconds> or a fixed width, into flags of BalancedGo
+func widthFlags(width string) ([]string, error) {
+	if width == "exact" {
+		return []string{"-exact"}, nil
+	}
+	if strings.HasPrefix(width, "approx:") {
+		if _, err := strconv.Atoi(width[len("approx:"):]); err != nil {
+			return nil, fmt.Errorf("approx needs a timeout in seconds, as in approx:60")
+		}
+		return []string{"-approx", width[len("approx:"):]}, nil
+	}
+	if k, err := strconv.Atoi(width); err != nil || k < 1 {
+		return nil, fmt.Errorf("unknown width mode %v", width)
+	}
+
+	return []string{"-width", width}, nil
+}
+
+// formatFlags chooses the input format of an instance by its file extension
+func formatFlags(path string) []string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".hgr", ".pace":
+		return []string{"-pace"}
+	case ".xml":
+		return []string{"-xcsp"}
+	}
+	return nil
+}
+
+// Args returns the arguments to run BalancedGo with the config on the given instance
+func (c Config) Args(instance string) ([]string, error) {
+	output := []string{"-bench", "-format", "json", "-graph", instance}
+	output = append(output, formatFlags(instance)...)
+
+	for _, f := range []func() ([]string, error){
+		func() ([]string, error) { return algorithmFlags(c.Algorithm) },
+		func() ([]string, error) { return preprocessingFlags(c.Preprocessing) },
+		func() ([]string, error) { return widthFlags(c.Width) },
+	} {
+		flags, err := f()
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, flags...)
+	}
+
+	if c.Heuristic > 0 {
+		output = append(output, "-heuristic", strconv.Itoa(c.Heuristic))
+	}
+
+	return output, nil
+}
+
+// matrix produces all combinations of the given comma-separated lists
+func matrix(algorithms, heuristics, preprocessings, widths string) ([]Config, error) {
+	var output []Config
+
+	for _, h := range strings.Split(heuristics, ",") {
+		heuristic, err := strconv.Atoi(strings.TrimSpace(h))
+		if err != nil {
+			return nil, fmt.Errorf("heuristic %v is not a number", h)
+		}
+		for _, a := range strings.Split(algorithms, ",") {
+			for _, p := range strings.Split(preprocessings, ",") {
+				for _, w := range strings.Split(widths, ",") {
+					c := Config{Algorithm: strings.TrimSpace(a), Heuristic: heuristic,
+						Preprocessing: strings.TrimSpace(p), Width: strings.TrimSpace(w)}
+					if _, err := c.Args(""); err != nil {
+						return nil, err
+					}
+					output = append(output, c)
+				}
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// instances collects all files in a directory and its subdirectories, sorted by path
+func instances(dir string, ext string) ([]string, error) {
+	var output []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && (ext == "" || strings.HasSuffix(path, ext)) {
+			output = append(output, path)
+		}
+		return nil
+	})
+	sort.Strings(output)
+
+	return output, err
+}
+
+// run executes BalancedGo with a config on one instance, and parses its output into a result
+func run(binary string, instance string, c Config, extra []string, timeout time.Duration) Result {
+	result := Result{Instance: instance, Config: c, Composition: make(map[string]float64)}
+
+	args, _ := c.Args(instance)
+	args = append(args, extra...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result.WallTime = float64(time.Since(start).Nanoseconds()) / 1e6
+	if cmd.ProcessState != nil {
+		result.MaxRSS = maxRSS(cmd.ProcessState)
+	}
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Status = StatusTimeout
+		return result
+	case err != nil:
+		result.Status = StatusError
+		result.Error = lastLine(stderr.String() + stdout.String())
+		return result
+	}
+
+	parseOutput(stdout.Bytes(), &result)
+
+	return result
+}
+
+// output is the part of the JSON output of BalancedGo used in the results
+type output struct {
+	Width   int
+	Correct bool
+	Time    float64
+	Times   []struct {
+		Label string
+		Time  float64
+	}
+}
+
+// parseOutput reads width, correctness and the time composition from the JSON output of BalancedGo
+func parseOutput(data []byte, result *Result) {
+	var out output
+	if err := json.Unmarshal(data, &out); err != nil {
+		result.Status = StatusError
+		result.Error = "no result in output: " + lastLine(string(data))
+		return
+	}
+
+	result.Width = out.Width
+	result.Correct = out.Correct
+	result.Time = out.Time
+	for _, t := range out.Times {
+		result.Composition[t.Label] = t.Time
+	}
+
+	switch {
+	case result.Correct:
+		result.Status = StatusSolved
+	case result.Width == 0:
+		result.Status = StatusRejected
+	default:
+		result.Status = StatusIncorrect
+	}
+}
+
+// lastLine returns the last non-empty line of an output, to keep error messages short
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func main() {
+	binary := flag.String("bin", "BalancedGo", "the path to the BalancedGo binary to benchmark")
+	dir := flag.String("dir", "", "the directory of instances, searched recursively. Files ending in .hgr or .pace are read\n"+
+		"in PACE 2019 format, files ending in .xml in XCSP3 format, and all others in HyperBench format")
+	ext := flag.String("ext", "", "only use instances whose file name ends in this suffix")
+	algorithms := flag.String("algorithms", "det", "comma-separated list of algorithms: local, global, det, localbip, auto,\n"+
+		"balDet:<depth>, seqBalDet:<depth>, hingePortfolio:<threshold>, portfolio:<algorithms joined by +>")
+	heuristics := flag.String("heuristics", "0", "comma-separated list of heuristics, as numbered by BalancedGo")
+	preprocessings := flag.String("preprocessing", "none", "comma-separated list of preprocessing, each either none or\n"+
+		"a combination joined by + of: g, t, h, blocks, safe")
+	widths := flag.String("widths", "exact", "comma-separated list of width modes: exact, approx:<seconds>, or a fixed width")
+	extra := flag.String("extra", "", "additional flags passed to every run, separated by spaces")
+	timeout := flag.Duration("timeout", time.Minute, "the timeout for each run")
+	outPath := flag.String("out", "", "the file for the results, written as JSON if it ends in .json, and as CSV otherwise.\n"+
+		"Results are printed as CSV to the standard output if not given")
+	compare := flag.String("compare", "", "compare an earlier result file against the one given with -to, instead of running")
+	to := flag.String("to", "", "used in combination with \"compare\": the later result file")
+	slowdown := flag.Float64("slowdown", 1.5, "used in combination with \"compare\": the factor by which a run has to be\n"+
+		"slower to count as a regression")
+	minTime := flag.Float64("minTime", 100, "used in combination with \"compare\": the time in ms a run has to be slower\n"+
+		"to count as a regression, so that noise on fast runs is ignored")
+
+	flag.Parse()
+
+	if *compare != "" {
+		if *to == "" {
+			flag.Usage()
+			os.Exit(1)
+		}
+		os.Exit(compareFiles(*compare, *to, *slowdown, *minTime))
+	}
+
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	configs, err := matrix(*algorithms, *heuristics, *preprocessings, *widths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	paths, err := instances(*dir, *ext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var results []Result
+	for _, path := range paths {
+		for _, c := range configs {
+			result := run(*binary, path, c, strings.Fields(*extra), *timeout)
+			fmt.Fprintf(os.Stderr, "%v %v: %v, width %v, %.2f ms\n", path, c, result.Status, result.Width,
+				result.WallTime)
+			results = append(results, result)
+		}
+	}
+
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
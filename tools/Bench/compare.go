@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// regressions lists the ways in which the later result of a run is worse than the earlier one
+func regressions(before, after Result, slowdown, minTime float64) []string {
+	var output []string
+
+	if before.Status == StatusSolved && after.Status != StatusSolved {
+		output = append(output, fmt.Sprintf("was solved, now %v", after.Status))
+	}
+	if before.Status == StatusSolved && after.Status == StatusSolved && after.Width > before.Width {
+		output = append(output, fmt.Sprintf("width increased from %v to %v", before.Width, after.Width))
+	}
+	if after.Status == StatusSolved && before.Status == StatusSolved && after.Time > before.Time*slowdown &&
+		after.Time-before.Time > minTime {
+		output = append(output, fmt.Sprintf("slower: %.2f ms instead of %.2f ms", after.Time, before.Time))
+	}
+
+	return output
+}
+
+// compareFiles prints all regressions between two result files, as well as a summary, and returns the exit code:
+// 1 if any regression was found or the files couldn't be read, 0 otherwise
+func compareFiles(beforePath, afterPath string, slowdown, minTime float64) int {
+	before, err := readResults(beforePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	after, err := readResults(afterPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	earlier := make(map[string]Result)
+	for _, r := range before {
+		earlier[r.key()] = r
+	}
+
+	var matched, regressed, newlySolved int
+	var timeBefore, timeAfter float64
+
+	for _, r := range after {
+		b, ok := earlier[r.key()]
+		if !ok {
+			continue
+		}
+		matched++
+
+		if b.Status != StatusSolved && r.Status == StatusSolved {
+			newlySolved++
+		}
+		if b.Status == StatusSolved && r.Status == StatusSolved {
+			timeBefore += b.Time
+			timeAfter += r.Time
+		}
+
+		if found := regressions(b, r, slowdown, minTime); len(found) > 0 {
+			regressed++
+			for _, reg := range found {
+				fmt.Printf("REGRESSION %v (%v): %v\n", r.Instance, r.Config, reg)
+			}
+		}
+	}
+
+	fmt.Printf("Compared %v runs: %v regressed, %v newly solved\n", matched, regressed, newlySolved)
+	fmt.Printf("Total time of runs solved in both: %.2f ms before, %.2f ms after\n", timeBefore, timeAfter)
+
+	if regressed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,3 @@
+module github.com/cem-okulmus/BalancedGo/tools/Bench
+
+go 1.14
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxRSS returns the maximal resident set size of a finished process in KB
+func maxRSS(state *os.ProcessState) int64 {
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(usage.Maxrss) / 1024 // reported in bytes on macOS
+	}
+	return 0
+}
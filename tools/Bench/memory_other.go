@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+import "os"
+
+// maxRSS is not available on this platform, and always returns 0
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
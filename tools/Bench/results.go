@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The possible outcomes of a run
+const (
+	StatusSolved    = "solved"    // a correct decomposition was found
+	StatusRejected  = "rejected"  // no decomposition of the width exists, or none was found
+	StatusIncorrect = "incorrect" // a decomposition was found, but it failed the correctness check
+	StatusTimeout   = "timeout"   // the run exceeded the timeout
+	StatusError     = "error"     // the run failed, or its output couldn't be read
+)
+
+// A Result records the outcome of running one config on one instance
+type Result struct {
+	Instance    string
+	Config      Config
+	Status      string
+	Width       int                // width of the decomposition, 0 if none was found
+	Correct     bool               // outcome of the correctness check
+	Time        float64            // the total time in ms, as reported by BalancedGo
+	Composition map[string]float64 // the time in ms of each step, as reported by BalancedGo
+	WallTime    float64            // the time in ms of the entire process
+	MaxRSS      int64              // the maximal resident set size of the process in KB, 0 if unavailable
+	Error       string             `json:",omitempty"`
+}
+
+// key identifies the instance and config of a result, to match results of different benchmark runs
+func (r Result) key() string {
+	return fmt.Sprintf("%v %v", r.Instance, r.Config)
+}
+
+var csvHeader = []string{"instance", "algorithm", "heuristic", "preprocessing", "widthMode", "status", "width",
+	"correct", "time", "composition", "wallTime", "maxRSS", "error"}
+
+// compositionString writes the time composition as label=ms pairs, separated by semicolons and ordered by label
+func compositionString(composition map[string]float64) string {
+	var labels []string
+	for label := range composition {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var pairs []string
+	for _, label := range labels {
+		pairs = append(pairs, label+"="+strconv.FormatFloat(composition[label], 'f', 5, 64))
+	}
+
+	return strings.Join(pairs, ";")
+}
+
+func parseComposition(s string) (map[string]float64, error) {
+	output := make(map[string]float64)
+	if s == "" {
+		return output, nil
+	}
+
+	for _, pair := range strings.Split(s, ";") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed time composition %v", s)
+		}
+		t, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		output[parts[0]] = t
+	}
+
+	return output, nil
+}
+
+// writeResults writes results to a file, as JSON if the path ends in .json, and as CSV otherwise. If the path is
+// empty, the results are written as CSV to the standard output.
+func writeResults(path string, results []Result) error {
+	if strings.HasSuffix(path, ".json") {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, data, 0644)
+	}
+
+	var out io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write(csvHeader)
+	for _, r := range results {
+		w.Write([]string{r.Instance, r.Config.Algorithm, strconv.Itoa(r.Config.Heuristic), r.Config.Preprocessing,
+			r.Config.Width, r.Status, strconv.Itoa(r.Width), strconv.FormatBool(r.Correct),
+			strconv.FormatFloat(r.Time, 'f', 5, 64), compositionString(r.Composition),
+			strconv.FormatFloat(r.WallTime, 'f', 5, 64), strconv.FormatInt(r.MaxRSS, 10), r.Error})
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+// readResults reads results written by writeResults
+func readResults(path string) ([]Result, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var output []Result
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &output)
+		return output, err
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("%v is not a result file: unexpected header", path)
+	}
+
+	for _, record := range records[1:] {
+		var r Result
+		var errs [6]error
+
+		r.Instance = record[0]
+		r.Config.Algorithm = record[1]
+		r.Config.Heuristic, errs[0] = strconv.Atoi(record[2])
+		r.Config.Preprocessing = record[3]
+		r.Config.Width = record[4]
+		r.Status = record[5]
+		r.Width, errs[1] = strconv.Atoi(record[6])
+		r.Correct, errs[2] = strconv.ParseBool(record[7])
+		r.Time, errs[3] = strconv.ParseFloat(record[8], 64)
+		r.Composition, errs[4] = parseComposition(record[9])
+		r.WallTime, errs[5] = strconv.ParseFloat(record[10], 64)
+		r.MaxRSS, _ = strconv.ParseInt(record[11], 10, 64)
+		r.Error = record[12]
+
+		for _, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", path, err)
+			}
+		}
+		output = append(output, r)
+	}
+
+	return output, nil
+}
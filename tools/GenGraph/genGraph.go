@@ -0,0 +1,109 @@
+// This package implements a tool to generate hypergraphs for tests and benchmarks, such as random hypergraphs, grids,
+// cycles, cliques, random conjunctive queries, and hypergraphs of a known hypertree width.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib/generate"
+)
+
+// fail reports an invalid choice of parameters and exits
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+
+func main() {
+	family := flag.String("type", "", "the family of hypergraphs to generate, one of:\n"+
+		"uniform, powerlaw, grid, cycle, clique, hw, cq")
+	vertices := flag.Int("vertices", 20, "the number of vertices (uniform, powerlaw), or of variables (cq)")
+	edges := flag.Int("edges", 20, "the number of edges (uniform, powerlaw), or of atoms (cq)")
+	arity := flag.Int("arity", 3, "the arity of edges (uniform, powerlaw), or their maximal arity (hw, cq)")
+	exponent := flag.Float64("exponent", 2, "the exponent of the power law (powerlaw)")
+	rows := flag.Int("rows", 4, "the number of rows (grid)")
+	cols := flag.Int("cols", 4, "the number of columns (grid)")
+	n := flag.Int("n", 5, "the number of vertices (cycle, clique)")
+	k := flag.Int("k", 2, "the hypertree width of the generated hypergraph (hw)")
+	nodes := flag.Int("nodes", 10, "the number of nodes of the underlying decomposition (hw)")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "the seed for the random generators")
+	pace := flag.Bool("pace", false, "output in PACE 2019 format instead of HyperBench format")
+	outPath := flag.String("out", "", "the path for outputting the hypergraph, instead of the standard output")
+
+	flag.Parse()
+
+	r := rand.New(rand.NewSource(*seed))
+	var h generate.Hypergraph
+
+	switch *family {
+	case "uniform":
+		if *vertices < 1 || *edges < 1 || *arity < 1 {
+			fail("Number of vertices, number of edges and arity must be positive.")
+		}
+		h = generate.Uniform(r, *vertices, *edges, *arity)
+	case "powerlaw":
+		if *vertices < 1 || *edges < 1 || *arity < 1 {
+			fail("Number of vertices, number of edges and arity must be positive.")
+		}
+		h = generate.PowerLaw(r, *vertices, *edges, *arity, *exponent)
+	case "grid":
+		if *rows < 1 || *cols < 1 || (*rows == 1 && *cols == 1) {
+			fail("Number of rows and columns must be positive, with at least two vertices in total.")
+		}
+		h = generate.Grid(*rows, *cols)
+	case "cycle":
+		if *n < 3 {
+			fail("A cycle needs at least 3 vertices.")
+		}
+		h = generate.Cycle(*n)
+	case "clique":
+		if *n < 2 {
+			fail("A clique needs at least 2 vertices.")
+		}
+		h = generate.Clique(*n)
+	case "hw":
+		if *k < 1 || *nodes < 1 {
+			fail("Width and number of nodes must be positive.")
+		}
+		h = generate.HypertreeWidth(r, *k, *nodes, *arity)
+	case "cq":
+		if *vertices < 1 || *edges < 1 || *arity < 1 {
+			fail("Number of variables, number of atoms and arity must be positive.")
+		}
+		h = generate.CQ(r, *edges, *vertices, *arity)
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// record how the hypergraph was generated, so that it can be reproduced
+	var args []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "seed" && f.Name != "out" && f.Name != "pace" {
+			args = append(args, "-"+f.Name+" "+f.Value.String())
+		}
+	})
+	args = append(args, "-seed "+fmt.Sprint(*seed))
+
+	var output string
+	if *pace {
+		output = "c GenGraph " + strings.Join(args, " ") + "\n" + h.PACE()
+	} else {
+		output = "% GenGraph " + strings.Join(args, " ") + "\n" + h.HyperBench()
+	}
+
+	if *outPath == "" {
+		fmt.Print(output)
+		return
+	}
+
+	if err := ioutil.WriteFile(*outPath, []byte(output), 0644); err != nil {
+		panic(err)
+	}
+}
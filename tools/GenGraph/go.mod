@@ -0,0 +1,7 @@
+module github.com/cem-okulmus/BalancedGo/tools/GenGraph
+
+go 1.14
+
+require github.com/cem-okulmus/BalancedGo v1.5.1
+
+replace github.com/cem-okulmus/BalancedGo => ../../
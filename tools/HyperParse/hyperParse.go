@@ -73,7 +73,8 @@ func main() {
 	decompPath := flag.String("decomp", "", "the file path to a decomposition in GML format")
 	outPath := flag.String("out", "", "the path for outputting the graph in PACE 2019 format")
 	outPathPACE := flag.String("outPACE", "", "the path for outputting the graph in PACE 2019 format")
-	statFlag := flag.Bool("stats", false, "output stats of the hypergraph")
+	statFlag := flag.Bool("stats", false, "output stats of the hypergraph, as CSV: avg. arity, vertices, edges, max. degree,\n"+
+		"max. arity, sum of arities, BIP, avg. degree, std. dev. of degrees, hinges, vertices and edges after GYÖ reduction")
 	starRedlag := flag.Bool("statsReduced", false, "output stats of the reduced hypergraph")
 
 	flag.Parse()
@@ -108,25 +109,12 @@ func main() {
 	}
 
 	if *statFlag {
-		sumEdgeSizes := 0
-		maxEdge := 0
-		maxDegree := 0
-
-		for _, e := range parsedGraph.Edges.Slice() {
-			sumEdgeSizes = sumEdgeSizes + len(e.Vertices)
-
-			if len(e.Vertices) > maxEdge {
-				maxEdge = len((e.Vertices))
-			}
-			degree := getDegree(parsedGraph.Edges, e)
-			if degree > maxDegree {
-				maxDegree = degree
-			}
-		}
-
-		average := float32(sumEdgeSizes) / float32(len(parsedGraph.Edges.Slice()))
+		features := lib.GetFeatures(parsedGraph)
 
-		fmt.Print(average, ",", len(parsedGraph.Vertices()), ",", len(parsedGraph.Edges.Slice()), ",", maxDegree, ",", maxEdge, ",", sumEdgeSizes)
+		fmt.Print(float32(features.AvgArity), ",", features.Vertices, ",", features.Edges, ",", features.MaxDegree, ",",
+			features.MaxArity, ",", features.SumArity, ",", features.BIP, ",", float32(features.AvgDegree), ",",
+			float32(features.DegreeStdDev), ",", features.Hinges, ",", features.ReducedVertices, ",",
+			features.ReducedEdges)
 
 		os.Exit(0)
 	}
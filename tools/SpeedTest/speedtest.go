@@ -48,6 +48,7 @@ func main() {
 
 	check := lib.BalancedCheck{}
 
+	var ws lib.CompWorkspace
 	gen := lib.SplitCombin(parsedGraph.Edges.Len(), *width, 1, true)[0]
 
 	startTime = time.Now()
@@ -61,7 +62,7 @@ func main() {
 
 		sep := lib.GetSubset(parsedGraph.Edges, j) // check new possible sep
 
-		if check.Check(&parsedGraph, &sep, 2) {
+		if check.Check(&parsedGraph, &sep, 2, &ws) {
 			gen.Found() // cache result
 
 			timePassed = time.Now().Sub(startTime)
@@ -0,0 +1,341 @@
+// This package implements a tool to inspect traces recorded by BalancedGo with the -trace flag. It summarises where
+// the search spent its time, i.e. the subgraphs visited most often, the subgraphs rejected repeatedly and the number
+// of steps at each depth, and it can render the search tree as text or in the DOT format of Graphviz.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// A step is a recursion step of the search, built from its events
+type step struct {
+	enter    lib.TraceEvent
+	seps     []lib.TraceEvent
+	children []*step
+	sep      int // the index of the separator of the parent whose component the step decomposes
+	exited   bool
+	accepted bool
+	end      int64
+}
+
+// duration returns the time in ms the step took, or until the end of the trace if it never returned
+func (s *step) duration(last int64) float64 {
+	end := s.end
+	if !s.exited {
+		end = last
+	}
+	return float64(end-s.enter.Time) / 1e6
+}
+
+func (s *step) outcome() string {
+	switch {
+	case !s.exited:
+		return "unfinished"
+	case s.accepted:
+		return "accepted"
+	}
+	return "rejected"
+}
+
+// buildTree orders the events into steps, and returns the top-level steps as well as the time of the last event
+func buildTree(events []lib.TraceEvent) ([]*step, map[int64]*step, int64, error) {
+	var roots []*step
+	steps := make(map[int64]*step)
+	var last int64
+
+	for _, e := range events {
+		if e.Time > last {
+			last = e.Time
+		}
+		if e.Kind == lib.TraceEnter {
+			s := &step{enter: e}
+			steps[e.Step] = s
+			if e.Parent == 0 {
+				roots = append(roots, s)
+			} else if parent, ok := steps[e.Parent]; ok {
+				s.sep = len(parent.seps) - 1
+				parent.children = append(parent.children, s)
+			} else {
+				return nil, nil, 0, fmt.Errorf("step %v entered before its parent %v", e.Step, e.Parent)
+			}
+			continue
+		}
+
+		s, ok := steps[e.Step]
+		if !ok {
+			return nil, nil, 0, fmt.Errorf("event %v of step %v, which was never entered", e.Kind, e.Step)
+		}
+		switch e.Kind {
+		case lib.TraceSeparator:
+			s.seps = append(s.seps, e)
+		case lib.TraceExit:
+			s.exited, s.accepted, s.end = true, e.Accepted, e.Time
+		default:
+			return nil, nil, 0, fmt.Errorf("unknown event %v", e.Kind)
+		}
+	}
+
+	return roots, steps, last, nil
+}
+
+// subgraphStats collects how often a subgraph was visited and rejected, and the time spent on it
+type subgraphStats struct {
+	hash     uint64
+	edges    int
+	visits   int
+	rejected int
+	time     float64
+}
+
+// summarise writes the totals, the depth histogram, the hottest subgraphs and the repeated failures of a trace
+func summarise(w io.Writer, roots []*step, steps map[int64]*step, last int64, top int) {
+	var accepted, rejected, unfinished, separators, subedges, maxDepth int
+	depths := make(map[int][2]int) // steps and rejections per depth
+	graphs := make(map[uint64]*subgraphStats)
+
+	for _, s := range steps {
+		switch s.outcome() {
+		case "accepted":
+			accepted++
+		case "rejected":
+			rejected++
+		default:
+			unfinished++
+		}
+		for _, sep := range s.seps {
+			separators++
+			if sep.Subedge {
+				subedges++
+			}
+		}
+
+		d := depths[s.enter.Depth]
+		d[0]++
+		if s.outcome() == "rejected" {
+			d[1]++
+		}
+		depths[s.enter.Depth] = d
+		if s.enter.Depth > maxDepth {
+			maxDepth = s.enter.Depth
+		}
+
+		g, ok := graphs[s.enter.Graph]
+		if !ok {
+			g = &subgraphStats{hash: s.enter.Graph, edges: s.enter.Edges}
+			graphs[s.enter.Graph] = g
+		}
+		g.visits++
+		if s.outcome() == "rejected" {
+			g.rejected++
+		}
+		g.time += s.duration(last)
+	}
+
+	var widths []string
+	for _, r := range roots {
+		widths = append(widths, fmt.Sprintf("%v (%v, %.2f ms)", r.enter.Width, r.outcome(), r.duration(last)))
+	}
+
+	fmt.Fprintf(w, "Steps: %v (%v accepted, %v rejected, %v unfinished) over %.2f ms\n", len(steps), accepted,
+		rejected, unfinished, float64(last)/1e6)
+	fmt.Fprintf(w, "Separators: %v, of which %v subedge variants\n", separators, subedges)
+	fmt.Fprintf(w, "Distinct subgraphs: %v\n", len(graphs))
+	fmt.Fprintf(w, "Top-level searches by width: %v\n", strings.Join(widths, ", "))
+
+	fmt.Fprintln(w, "\nSteps by depth (rejected):")
+	most := 0
+	for _, d := range depths {
+		if d[0] > most {
+			most = d[0]
+		}
+	}
+	for depth := 1; depth <= maxDepth; depth++ {
+		d := depths[depth]
+		fmt.Fprintf(w, "%4d %8d (%8d) %s\n", depth, d[0], d[1], strings.Repeat("#", (d[0]*50+most-1)/most))
+	}
+
+	var list []*subgraphStats
+	for _, g := range graphs {
+		list = append(list, g)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].visits != list[j].visits {
+			return list[i].visits > list[j].visits
+		}
+		return list[i].hash < list[j].hash
+	})
+	fmt.Fprintln(w, "\nHot subgraphs:")
+	printSubgraphs(w, list, top, func(g *subgraphStats) bool { return true })
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].rejected != list[j].rejected {
+			return list[i].rejected > list[j].rejected
+		}
+		return list[i].hash < list[j].hash
+	})
+	repeated, wasted := 0, 0.0
+	for _, g := range list {
+		if g.rejected > 1 {
+			repeated++
+			wasted += g.time * float64(g.rejected-1) / float64(g.visits)
+		}
+	}
+	fmt.Fprintf(w, "\nRepeated failures: %v subgraphs rejected more than once, about %.2f ms spent on repetitions\n",
+		repeated, wasted)
+	printSubgraphs(w, list, top, func(g *subgraphStats) bool { return g.rejected > 1 })
+}
+
+func printSubgraphs(w io.Writer, list []*subgraphStats, top int, filter func(*subgraphStats) bool) {
+	for i, printed := 0, 0; i < len(list) && printed < top; i++ {
+		g := list[i]
+		if !filter(g) {
+			continue
+		}
+		fmt.Fprintf(w, "  %016x  %4d edges  %6d visits  %6d rejected  %10.2f ms\n", g.hash, g.edges, g.visits,
+			g.rejected, g.time)
+		printed++
+	}
+}
+
+// separatorString writes the names of the edges in a separator, with 0 standing for subedges
+func separatorString(sep lib.TraceEvent) string {
+	var names []string
+	for _, n := range sep.Separator {
+		names = append(names, strconv.Itoa(n))
+	}
+	output := "{" + strings.Join(names, ",") + "}"
+	if sep.Subedge {
+		output = output + " (subedge)"
+	}
+	return output
+}
+
+// printTree renders the subtree of a step as indented text, up to the given depth
+func printTree(w io.Writer, s *step, last int64, maxDepth int, indent string) {
+	fmt.Fprintf(w, "%s#%v H=%016x (%v edges, width %v) %v, %.2f ms\n", indent, s.enter.Step, s.enter.Graph,
+		s.enter.Edges, s.enter.Width, s.outcome(), s.duration(last))
+
+	if maxDepth > 0 && s.enter.Depth >= maxDepth {
+		if len(s.children) > 0 {
+			fmt.Fprintf(w, "%s  ... %v calls\n", indent, len(s.children))
+		}
+		return
+	}
+
+	for i, sep := range s.seps {
+		fmt.Fprintf(w, "%s  sep %v -> %v components\n", indent, separatorString(sep), len(sep.Components))
+		for _, c := range s.children {
+			if c.sep == i {
+				printTree(w, c, last, maxDepth, indent+"    ")
+			}
+		}
+	}
+}
+
+// writeDot renders the search tree in the DOT format, up to the given depth
+func writeDot(w io.Writer, roots []*step, last int64, maxDepth int) {
+	fmt.Fprintln(w, "digraph trace {")
+	fmt.Fprintln(w, "  node [shape=box, fontname=monospace];")
+
+	var visit func(s *step)
+	visit = func(s *step) {
+		color := map[string]string{"accepted": "darkgreen", "rejected": "red", "unfinished": "gray"}[s.outcome()]
+		label := fmt.Sprintf("#%v\\n%016x\\n%v edges, %.2f ms", s.enter.Step, s.enter.Graph, s.enter.Edges,
+			s.duration(last))
+		if len(s.seps) > 0 {
+			label = label + "\\n" + strconv.Itoa(len(s.seps)) + " separators"
+		}
+		fmt.Fprintf(w, "  s%v [label=\"%v\", color=%v];\n", s.enter.Step, label, color)
+
+		if maxDepth > 0 && s.enter.Depth >= maxDepth {
+			return
+		}
+		for _, c := range s.children {
+			fmt.Fprintf(w, "  s%v -> s%v;\n", s.enter.Step, c.enter.Step)
+			visit(c)
+		}
+	}
+	for _, r := range roots {
+		visit(r)
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+func main() {
+	tracePath := flag.String("trace", "", "the trace to inspect, as written by BalancedGo with the -trace flag")
+	top := flag.Int("top", 10, "the number of subgraphs listed in the summary")
+	tree := flag.Bool("tree", false, "render the search tree as text instead of summarising the trace")
+	dotPath := flag.String("dot", "", "render the search tree in DOT format into the specified file")
+	maxDepth := flag.Int("maxDepth", 0, "used in combination with \"tree\" or \"dot\": only render steps up to this depth")
+	rootStep := flag.Int64("step", 0, "used in combination with \"tree\" or \"dot\": only render the subtree of this step")
+
+	flag.Parse()
+
+	if *tracePath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	f, err := os.Open(*tracePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	events, err := lib.ReadTrace(f)
+	f.Close()
+	if err != nil { // a trace cut off by a timeout may end with a partial event
+		fmt.Fprintln(os.Stderr, "Reading trace stopped:", err)
+	}
+
+	roots, steps, last, err := buildTree(events)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *rootStep != 0 {
+		s, ok := steps[*rootStep]
+		if !ok {
+			fmt.Fprintln(os.Stderr, "No step", *rootStep, "in the trace")
+			os.Exit(1)
+		}
+		roots = []*step{s}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	if *dotPath != "" {
+		dot, err := os.Create(*dotPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		w := bufio.NewWriter(dot)
+		writeDot(w, roots, last, *maxDepth)
+		w.Flush()
+		dot.Close()
+	}
+
+	if *tree {
+		for _, r := range roots {
+			printTree(out, r, last, *maxDepth, "")
+		}
+		return
+	}
+
+	if *dotPath == "" {
+		summarise(out, roots, steps, last, *top)
+	}
+}